@@ -0,0 +1,136 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"sync"
+)
+
+// ComponentKey is the key of the field that holds the name
+// of the component an entry was logged by.
+const ComponentKey = "component"
+
+type (
+	// components holds the loggers created with Component,
+	// keyed by name.
+	components struct {
+		mtx     sync.Mutex
+		loggers map[string]*component
+	}
+	// component is the logrus Logger of a component, if the
+	// level hasn't been set it follows the Logger's level.
+	component struct {
+		logger *logrus.Logger
+		level  *logrus.Level
+	}
+)
+
+// Component returns an entry for the named component of
+// the default Logger, see Logger.Component.
+func Component(name string) *logrus.Entry {
+	return std.Component(name)
+}
+
+// Component returns an entry for the named component, such
+// as "db" or "http", which is gated by the component's
+// own level rather than the Logger's. This allows debug
+// logging to be turned on for a single component without
+// changing the level of everything else. Until a level
+// is set with SetComponentLevel, the component follows
+// the level of the Logger. Entries are sent to the same
+// sinks as the Logger, with the name of the component
+// within the fields under ComponentKey.
+func (l *Logger) Component(name string) *logrus.Entry {
+	c := l.components.get(l, name)
+	return c.logger.WithFields(logrus.Fields{types.FieldKey: logrus.Fields{
+		ComponentKey: name,
+	}})
+}
+
+// SetComponentLevel sets the level of the named component,
+// the component doesn't have to exist yet.
+func (l *Logger) SetComponentLevel(name string, level logrus.Level) {
+	c := l.components.get(l, name)
+	l.components.mtx.Lock()
+	defer l.components.mtx.Unlock()
+	c.level = &level
+	c.logger.SetLevel(level)
+}
+
+// ComponentLevels returns the current level of every
+// component that has been created or had its level
+// set.
+func (l *Logger) ComponentLevels() map[string]logrus.Level {
+	l.components.mtx.Lock()
+	defer l.components.mtx.Unlock()
+	levels := make(map[string]logrus.Level, len(l.components.loggers))
+	for name, c := range l.components.loggers {
+		levels[name] = c.logger.GetLevel()
+	}
+	return levels
+}
+
+// Components returns the names of the components of the
+// Logger in order.
+func (l *Logger) Components() []string {
+	l.components.mtx.Lock()
+	defer l.components.mtx.Unlock()
+	names := make([]string, 0, len(l.components.loggers))
+	for name := range l.components.loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetLevel sets the level of the Logger along with the
+// components that haven't had their own level set.
+func (l *Logger) SetLevel(level logrus.Level) {
+	l.Logger.SetLevel(level)
+	l.components.mtx.Lock()
+	defer l.components.mtx.Unlock()
+	for _, c := range l.components.loggers {
+		if c.level == nil {
+			c.logger.SetLevel(level)
+		}
+	}
+}
+
+// get returns the component with the name, creating it if
+// it doesn't exist. The component's logrus Logger shares
+// the hooks and formatter of the Logger.
+func (cs *components) get(l *Logger, name string) *component {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+	if cs.loggers == nil {
+		cs.loggers = make(map[string]*component)
+	}
+	c, ok := cs.loggers[name]
+	if ok {
+		return c
+	}
+	cl := logrus.New()
+	cl.Out = l.Out
+	cl.Hooks = l.Hooks
+	cl.Formatter = l.Formatter
+	cl.ReportCaller = l.ReportCaller
+	cl.ExitFunc = l.Logger.Exit
+	cl.SetLevel(l.GetLevel())
+	c = &component{logger: cl}
+	cs.loggers[name] = c
+	return c
+}
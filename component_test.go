@@ -0,0 +1,82 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"sync"
+)
+
+func (t *LoggerTestSuite) TestLogger_Component() {
+	var (
+		mtx sync.Mutex
+		got []types.Entry
+	)
+	n := NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		got = append(got, entry)
+		return nil
+	})
+
+	l, err := NewLogger(context.Background(), NewOptions().
+		Service("service").
+		Level(logrus.InfoLevel).
+		WithNamedNotifier("custom", n, nil, nil))
+	t.NoError(err)
+	defer l.Close(context.Background())
+
+	// Debug is enabled for the db component only.
+	l.SetComponentLevel("db", logrus.DebugLevel)
+	l.Component("db").Debug("query")
+	l.Component("http").Debug("request")
+	l.Debug("global")
+	t.NoError(l.Flush(context.Background()))
+
+	mtx.Lock()
+	t.Len(got, 1)
+	t.Equal("query", got[0].Message)
+	t.Equal("db", got[0].Fields()[ComponentKey])
+	got = nil
+	mtx.Unlock()
+
+	t.Equal(map[string]logrus.Level{"db": logrus.DebugLevel, "http": logrus.InfoLevel}, l.ComponentLevels())
+	t.Equal([]string{"db", "http"}, l.Components())
+
+	// Components without their own level follow the Logger.
+	l.SetLevel(logrus.WarnLevel)
+	t.Equal(map[string]logrus.Level{"db": logrus.DebugLevel, "http": logrus.WarnLevel}, l.ComponentLevels())
+	l.Component("http").Info("request")
+	l.Component("db").Info("query")
+	t.NoError(l.Flush(context.Background()))
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	t.Len(got, 1)
+	t.Equal("query", got[0].Message)
+}
+
+func (t *LoggerTestSuite) TestComponent() {
+	orig := std
+	defer SetDefault(orig)
+
+	l, err := NewLogger(context.TODO(), NewOptions().Service("service"))
+	t.NoError(err)
+	SetDefault(l)
+
+	Component("db")
+	t.Equal([]string{"db"}, l.Components())
+}
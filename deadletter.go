@@ -0,0 +1,190 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/dlq"
+	"github.com/ainsleyclark/logger/internal/retry"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	// DefaultDeadLetterMaxSize is the default maximum size
+	// of the dead letter queue in bytes, 100MB.
+	DefaultDeadLetterMaxSize = 100 << 20
+	// DefaultDeadLetterReplayInterval is the default
+	// interval in which entries within the dead letter
+	// queue are replayed.
+	DefaultDeadLetterReplayInterval = time.Second * 30
+	// deadLetterSendTimeout is the maximum amount of time
+	// spent sending a single replayed entry.
+	deadLetterSendTimeout = time.Second * 30
+)
+
+// deadLetterConfig is the configuration used for storing
+// failed deliveries on disk.
+type deadLetterConfig struct {
+	Dir            string
+	MaxSize        int64
+	ReplayInterval time.Duration
+}
+
+// DeadLetter stores entries that fail to be delivered to
+// Mongo or a notifier, after all retries, within the
+// directory passed. Stored entries are replayed on
+// start, every DefaultDeadLetterReplayInterval and
+// as soon as the destination recovers. Once the
+// queue reaches the maximum size in bytes, new
+// failures are passed to OnError. If maxSize is
+// zero, DefaultDeadLetterMaxSize is used.
+func (op *Options) DeadLetter(dir string, maxSize int64) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.deadLetter.Dir = dir
+		config.deadLetter.MaxSize = maxSize
+	})
+	return op
+}
+
+// addDeadLetter opens the dead letter queue if a directory
+// has been set and starts replaying stored entries.
+func (hook *defaultHook) addDeadLetter() error {
+	if hook.config.deadLetter.Dir == "" {
+		return nil
+	}
+	q, err := dlq.Open(hook.config.deadLetter.Dir, hook.config.deadLetter.MaxSize)
+	if err != nil {
+		return errors.NewInternal(err, "Error opening the dead letter queue", "Logger.DeadLetter")
+	}
+	hook.dlq = q
+	ctx, cancel := context.WithCancel(context.Background())
+	hook.replayNow = make(chan struct{}, 1)
+	hook.replayCancel = cancel
+	hook.replayDone = make(chan struct{})
+	go hook.runReplay(ctx, hook.config.deadLetter.ReplayInterval)
+	return nil
+}
+
+// deadLetter stores the entries that failed to be delivered
+// to the sink so that they can be replayed.
+func (hook *defaultHook) deadLetter(sink string, entries ...types.Entry) {
+	if hook.dlq == nil {
+		return
+	}
+	for _, entry := range entries {
+		err := hook.dlq.Push(dlq.NewRecord(sink, entry))
+		if err != nil {
+			hook.logError(errors.NewInternal(err, "Error storing entry in the dead letter queue", "Logger.DeadLetter"))
+			return
+		}
+	}
+}
+
+// recovered is called after an entry has been delivered
+// successfully, if there are entries waiting within the
+// dead letter queue they are replayed.
+func (hook *defaultHook) recovered() {
+	if hook.dlq == nil || hook.dlq.Size() == 0 {
+		return
+	}
+	select {
+	case hook.replayNow <- struct{}{}:
+	default:
+	}
+}
+
+// runReplay replays the dead letter queue on start, on
+// every interval and when a destination has recovered,
+// until the context is cancelled.
+func (hook *defaultHook) runReplay(ctx context.Context, interval time.Duration) {
+	defer close(hook.replayDone)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		hook.replay(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-hook.replayNow:
+		}
+	}
+}
+
+// replay sends the entries within the dead letter queue to
+// their sinks. Once a sink fails, the rest of its entries
+// are kept until the next replay. Entries that fail
+// permanently are removed.
+func (hook *defaultHook) replay(ctx context.Context) {
+	const op = "Logger.Replay"
+	failed := map[string]bool{}
+	_, _ = hook.dlq.Replay(ctx, func(r dlq.Record) error {
+		if failed[r.Sink] {
+			return fmt.Errorf("%s is unavailable", r.Sink)
+		}
+		err := hook.redeliver(ctx, r)
+		if retry.IsPermanent(err) {
+			hook.logError(errors.NewInternal(err, "Error replaying entry to "+r.Sink, op))
+			return nil
+		}
+		if err != nil {
+			failed[r.Sink] = true
+		}
+		return err
+	})
+}
+
+// redeliver sends the record to the sink it failed to be
+// delivered to, records are stored with the unique name
+// of the sink. Each send is limited to
+// deadLetterSendTimeout.
+func (hook *defaultHook) redeliver(ctx context.Context, r dlq.Record) error {
+	entry := r.Entry()
+	if r.Sink == SinkMongo {
+		if hook.mogrus == nil {
+			return fmt.Errorf("%s is not configured", r.Sink)
+		}
+		e := logrus.Entry(entry)
+		e.Logger = hook.logger
+		return hook.mogrus(&e)
+	}
+	n, ok := hook.notifier(r.Sink)
+	if !ok {
+		return fmt.Errorf("%s is not configured", r.Sink)
+	}
+	ctx, cancel := context.WithTimeout(ctx, deadLetterSendTimeout)
+	defer cancel()
+	return n.Notifier.Send(ctx, entry, hook.args(n))
+}
+
+// stopReplay cancels the replay of the dead letter queue
+// and waits for it to stop or until the context is
+// cancelled, in which case the context's error is
+// returned.
+func (hook *defaultHook) stopReplay(ctx context.Context) error {
+	if hook.replayCancel == nil {
+		return nil
+	}
+	hook.replayCancel()
+	select {
+	case <-hook.replayDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
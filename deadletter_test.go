@@ -0,0 +1,328 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/retry"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+func (t *LoggerTestSuite) TestDeadLetter_Options() {
+	opts := NewOptions().DeadLetter("dir", 10)
+	c := &Config{}
+	for _, optFn := range opts.optFuncs {
+		optFn(c)
+	}
+	t.Equal("dir", c.deadLetter.Dir)
+	t.Equal(int64(10), c.deadLetter.MaxSize)
+}
+
+// newDeadLetterHook returns a hook with the dead letter
+// queue enabled that sends entries to the notifier.
+func (t *LoggerTestSuite) newDeadLetterHook(dir string, maxSize int64, n NotifierFunc) *defaultHook {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	c := &Config{
+		retry:      RetryPolicy{MaxAttempts: 1},
+		deadLetter: deadLetterConfig{Dir: dir, MaxSize: maxSize, ReplayInterval: time.Hour},
+		onError:    func(err error) {},
+	}
+	hook := &defaultHook{
+		config: c,
+		logger: l,
+		notifiers: []notifierConfig{
+			{Name: "notifier", Notifier: n, Report: types.DefaultReportFn},
+		},
+	}
+	t.NoError(hook.addDeadLetter())
+	return hook
+}
+
+func (t *LoggerTestSuite) TestDeadLetter() {
+	var (
+		dir  = t.T().TempDir()
+		fail int32
+		sent = make(chan string, 10)
+	)
+	atomic.StoreInt32(&fail, 1)
+
+	n := func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		if atomic.LoadInt32(&fail) == 1 {
+			return errors.New("unavailable")
+		}
+		sent <- entry.Message
+		return nil
+	}
+
+	hook := t.newDeadLetterHook(dir, DefaultDeadLetterMaxSize, n)
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "first"}))
+	t.NoError(hook.flush(context.Background()))
+	t.Equal(1, hook.stats().DeadLetter)
+
+	// Stored entries are replayed once the notifier recovers.
+	atomic.StoreInt32(&fail, 0)
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "second"}))
+	t.NoError(hook.flush(context.Background()))
+
+	got := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-sent:
+			got[msg] = true
+		case <-time.After(time.Second * 5):
+			t.FailNow("timed out waiting for replay")
+		}
+	}
+	t.Equal(map[string]bool{"first": true, "second": true}, got)
+	t.Eventually(func() bool {
+		return hook.stats().DeadLetter == 0
+	}, time.Second*5, time.Millisecond*10)
+	t.NoError(hook.close(context.Background()))
+}
+
+func (t *LoggerTestSuite) TestDeadLetter_Sink() {
+	var (
+		dir  = t.T().TempDir()
+		fail int32
+		sent = make(chan string, 10)
+	)
+	atomic.StoreInt32(&fail, 1)
+
+	send := func(name string, shouldFail bool) NotifierFunc {
+		return func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+			if shouldFail && atomic.LoadInt32(&fail) == 1 {
+				return errors.New("unavailable")
+			}
+			sent <- name + ":" + entry.Message
+			return nil
+		}
+	}
+
+	// Both notifiers are the same type, entries should only
+	// be replayed to the one that failed.
+	hook := t.newDeadLetterHook(dir, DefaultDeadLetterMaxSize, send("first", false))
+	hook.notifiers = append(hook.notifiers, notifierConfig{Name: "second", Notifier: send("second", true), Report: types.DefaultReportFn})
+
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "message"}))
+	t.NoError(hook.flush(context.Background()))
+	t.Equal("first:message", <-sent)
+	t.Equal(1, hook.stats().DeadLetter)
+
+	atomic.StoreInt32(&fail, 0)
+	hook.recovered()
+	select {
+	case msg := <-sent:
+		t.Equal("second:message", msg)
+	case <-time.After(time.Second * 5):
+		t.FailNow("timed out waiting for replay")
+	}
+	t.Eventually(func() bool {
+		return hook.stats().DeadLetter == 0
+	}, time.Second*5, time.Millisecond*10)
+	t.NoError(hook.close(context.Background()))
+	t.Empty(sent)
+}
+
+func (t *LoggerTestSuite) TestDeadLetter_Restart() {
+	dir := t.T().TempDir()
+
+	hook := t.newDeadLetterHook(dir, DefaultDeadLetterMaxSize, func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		return errors.New("unavailable")
+	})
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "message"}))
+	t.NoError(hook.close(context.Background()))
+	t.Equal(1, hook.stats().DeadLetter)
+
+	// Entries stored by a previous process are replayed on
+	// start.
+	sent := make(chan types.Entry, 1)
+	hook = t.newDeadLetterHook(dir, DefaultDeadLetterMaxSize, func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		sent <- entry
+		return nil
+	})
+	select {
+	case entry := <-sent:
+		t.Equal("message", entry.Message)
+		t.Equal(logrus.ErrorLevel, entry.Level)
+	case <-time.After(time.Second * 5):
+		t.FailNow("timed out waiting for replay")
+	}
+	t.NoError(hook.close(context.Background()))
+	t.Equal(0, hook.stats().DeadLetter)
+}
+
+func (t *LoggerTestSuite) TestDeadLetter_CloseReplaying() {
+	tt := map[string]struct {
+		send func(ctx context.Context, release chan struct{})
+		want string
+	}{
+		"Cancelled": {
+			func(ctx context.Context, release chan struct{}) {
+				<-ctx.Done()
+			},
+			"",
+		},
+		"Stuck": {
+			func(ctx context.Context, release chan struct{}) {
+				<-release
+			},
+			"Error waiting for the dead letter queue to stop replaying",
+		},
+	}
+
+	for name, test := range tt {
+		test := test
+		t.Run(name, func() {
+			dir := t.T().TempDir()
+			hook := t.newDeadLetterHook(dir, DefaultDeadLetterMaxSize, func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+				return errors.New("unavailable")
+			})
+			t.NoError(hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "message"}))
+			t.NoError(hook.close(context.Background()))
+
+			// The replay on start hangs until it's cancelled or
+			// released.
+			var (
+				started = make(chan struct{})
+				release = make(chan struct{})
+			)
+			defer close(release)
+			hook = t.newDeadLetterHook(dir, DefaultDeadLetterMaxSize, func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+				close(started)
+				test.send(ctx, release)
+				return ctx.Err()
+			})
+			<-started
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+			defer cancel()
+			start := time.Now()
+			err := hook.close(ctx)
+			t.Less(time.Since(start), time.Second)
+			if test.want == "" {
+				t.NoError(err)
+				return
+			}
+			t.ErrorContains(err, test.want)
+		})
+	}
+}
+
+func (t *LoggerTestSuite) TestDeadLetter_Mongo() {
+	var (
+		dir  = t.T().TempDir()
+		fail int32
+		sent = make(chan string, 10)
+	)
+	atomic.StoreInt32(&fail, 1)
+
+	hook := t.newDeadLetterHook(dir, DefaultDeadLetterMaxSize, nil)
+	hook.notifiers = nil
+	hook.config.mongo = mongoConfig{Report: types.DefaultReportFn}
+	hook.mogrus = func(entry *logrus.Entry) error {
+		if atomic.LoadInt32(&fail) == 1 {
+			return errors.New("mongo unavailable")
+		}
+		sent <- entry.Message
+		return nil
+	}
+	hook.mongo = hook.newMongoQueue()
+
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "first"}))
+	t.NoError(hook.flush(context.Background()))
+	t.Equal(1, hook.stats().DeadLetter)
+
+	atomic.StoreInt32(&fail, 0)
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "second"}))
+
+	got := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-sent:
+			got[msg] = true
+		case <-time.After(time.Second * 5):
+			t.FailNow("timed out waiting for replay")
+		}
+	}
+	t.Equal(map[string]bool{"first": true, "second": true}, got)
+	t.NoError(hook.close(context.Background()))
+}
+
+func (t *LoggerTestSuite) TestDeadLetter_Permanent() {
+	var calls int32
+	hook := t.newDeadLetterHook(t.T().TempDir(), DefaultDeadLetterMaxSize, func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		atomic.AddInt32(&calls, 1)
+		return retry.Permanent(errors.New("bad request"))
+	})
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "message"}))
+	t.NoError(hook.flush(context.Background()))
+
+	// Entries that can never be delivered aren't stored.
+	t.Equal(int32(1), atomic.LoadInt32(&calls))
+	t.Equal(0, hook.stats().DeadLetter)
+	t.NoError(hook.close(context.Background()))
+}
+
+func (t *LoggerTestSuite) TestDeadLetter_ReplayPermanent() {
+	dir := t.T().TempDir()
+
+	hook := t.newDeadLetterHook(dir, DefaultDeadLetterMaxSize, func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		return errors.New("unavailable")
+	})
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "message"}))
+	t.NoError(hook.close(context.Background()))
+	t.Equal(1, hook.stats().DeadLetter)
+
+	// Stored entries that fail permanently on replay are
+	// removed rather than replayed forever.
+	hook = t.newDeadLetterHook(dir, DefaultDeadLetterMaxSize, func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		return retry.Permanent(errors.New("bad request"))
+	})
+	t.Eventually(func() bool {
+		return hook.stats().DeadLetter == 0
+	}, time.Second*5, time.Millisecond*10)
+	t.NoError(hook.close(context.Background()))
+}
+
+func (t *LoggerTestSuite) TestDeadLetter_Full() {
+	var got int32
+	hook := t.newDeadLetterHook(t.T().TempDir(), 1, func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		return errors.New("unavailable")
+	})
+	hook.config.onError = func(err error) {
+		if errors.Message(err) == "Error storing entry in the dead letter queue" {
+			atomic.AddInt32(&got, 1)
+		}
+	}
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "message"}))
+	t.NoError(hook.flush(context.Background()))
+	t.Equal(int32(1), atomic.LoadInt32(&got))
+	t.Equal(0, hook.stats().DeadLetter)
+	t.NoError(hook.close(context.Background()))
+}
+
+func (t *LoggerTestSuite) TestDeadLetter_Disabled() {
+	hook := &defaultHook{config: &Config{}}
+	t.NoError(hook.addDeadLetter())
+	t.Nil(hook.dlq)
+	hook.deadLetter("sink", types.Entry{})
+	hook.recovered()
+	t.NoError(hook.stopReplay(context.Background()))
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"github.com/ainsleyclark/logger/internal/digest"
+	"github.com/ainsleyclark/logger/types"
+	"time"
+)
+
+// digestConfig is the configuration used for sending
+// entries to notifiers as periodic digests.
+type digestConfig struct {
+	Window    time.Duration
+	Report    types.ShouldReportFunc
+	Notifiers []string
+}
+
+// Digest collects the entries that fn reports over the
+// window and sends them as a single message, grouped by
+// the error's code and operation with the count, first
+// and last seen times and a sample of each group.
+// Other entries are sent as normal. If no notifiers
+// are passed, digests are used for every notifier,
+// otherwise only for those named, such as "slack" or
+// "workplace". If fn is nil, every entry is digested.
+// Digests are sent early when a Fatal or Panic entry
+// is logged, before the process exits.
+func (op *Options) Digest(window time.Duration, fn types.ShouldReportFunc, notifiers ...string) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.digest.Window = window
+		config.digest.Report = fn
+		config.digest.Notifiers = notifiers
+	})
+	return op
+}
+
+// addDigests attaches an Aggregator to each notifier that
+// digests are enabled for, digests are delivered to the
+// notifier the entries were collected for.
+func (hook *defaultHook) addDigests() {
+	if hook.config.digest.Window <= 0 {
+		return
+	}
+	for i := range hook.notifiers {
+		n := &hook.notifiers[i]
+		if !hook.config.digest.enabled(n.Name) {
+			continue
+		}
+		n.digest = digest.New(hook.config.digest.Window, func(d digest.Digest) {
+			hook.deliver(*n, digestEntry(d))
+		})
+	}
+}
+
+// flushDigests sends the entries waiting to be digested
+// without stopping the digest windows.
+func (hook *defaultHook) flushDigests() {
+	for _, n := range hook.notifiers {
+		if n.digest != nil {
+			n.digest.Flush()
+		}
+	}
+}
+
+// closeDigests sends the entries waiting to be digested
+// and stops the digest windows.
+func (hook *defaultHook) closeDigests() {
+	for _, n := range hook.notifiers {
+		if n.digest != nil {
+			n.digest.Close()
+		}
+	}
+}
+
+// enabled determines if digests are used for the notifier.
+func (c digestConfig) enabled(name string) bool {
+	if len(c.Notifiers) == 0 {
+		return true
+	}
+	for _, n := range c.Notifiers {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// digestEntry returns the entry sent to a notifier when a
+// digest window ends.
+func digestEntry(d digest.Digest) types.Entry {
+	return types.Entry{
+		Level:   d.Level(),
+		Time:    d.End,
+		Message: d.Message(),
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+func (t *LoggerTestSuite) TestDigest_Options() {
+	opts := NewOptions().Digest(time.Minute, types.DefaultReportFn, "slack")
+	c := &Config{}
+	for _, optFn := range opts.optFuncs {
+		optFn(c)
+	}
+	t.Equal(time.Minute, c.digest.Window)
+	t.NotNil(c.digest.Report)
+	t.Equal([]string{"slack"}, c.digest.Notifiers)
+}
+
+func (t *LoggerTestSuite) TestDigest() {
+	type sent struct {
+		name  string
+		entry types.Entry
+	}
+	var (
+		mtx  sync.Mutex
+		got  []sent
+		send = func(name string) NotifierFunc {
+			return func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+				mtx.Lock()
+				defer mtx.Unlock()
+				got = append(got, sent{name, entry})
+				return nil
+			}
+		}
+	)
+
+	hook := &defaultHook{
+		logger: logrus.New(),
+		config: &Config{
+			digest: digestConfig{
+				Window: time.Hour,
+				Report: func(e types.Entry) bool {
+					return e.Level == logrus.WarnLevel
+				},
+				Notifiers: []string{"slack"},
+			},
+		},
+		notifiers: []notifierConfig{
+			{Name: "slack", Notifier: send("slack"), Report: types.DefaultReportFn},
+			{Name: "workplace", Notifier: send("workplace"), Report: types.DefaultReportFn},
+		},
+	}
+	hook.addDigests()
+	t.NotNil(hook.notifiers[0].digest)
+	t.Nil(hook.notifiers[1].digest)
+
+	for i := 0; i < 3; i++ {
+		t.NoError(hook.Fire(&logrus.Entry{Level: logrus.WarnLevel, Message: "slow request"}))
+	}
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "error"}))
+	t.NoError(hook.flush(context.Background()))
+
+	// Warnings are held back for Slack, errors are sent
+	// straight away.
+	mtx.Lock()
+	t.Len(got, 5)
+	for _, s := range got {
+		if s.name == "slack" {
+			t.Equal("error", s.entry.Message)
+		}
+	}
+	got = nil
+	mtx.Unlock()
+
+	t.NoError(hook.close(context.Background()))
+	mtx.Lock()
+	defer mtx.Unlock()
+	t.Len(got, 1)
+	t.Equal("slack", got[0].name)
+	t.Equal(logrus.WarnLevel, got[0].entry.Level)
+	t.Contains(got[0].entry.Message, "Digest of 3 entries")
+	t.Contains(got[0].entry.Message, "Sample: slow request")
+}
+
+func (t *LoggerTestSuite) TestDigest_Disabled() {
+	hook := &defaultHook{
+		config:    &Config{},
+		notifiers: []notifierConfig{{Name: "notifier"}},
+	}
+	hook.addDigests()
+	t.Nil(hook.notifiers[0].digest)
+}
+
+func (t *LoggerTestSuite) TestDigest_Fatal() {
+	var (
+		mtx sync.Mutex
+		got []types.Entry
+	)
+	n := NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		got = append(got, entry)
+		return nil
+	})
+
+	l, err := NewLogger(context.Background(), NewOptions().
+		Service("service").
+		WithNamedNotifier("custom", n, nil, nil).
+		Digest(time.Hour, nil))
+	t.NoError(err)
+	defer l.Close(context.Background())
+	l.Logger.Out = &bytes.Buffer{}
+	l.Logger.ExitFunc = func(int) {}
+
+	l.Fatal("shutting down")
+
+	// The digest is sent before the process exits, without
+	// waiting for the window to end.
+	mtx.Lock()
+	defer mtx.Unlock()
+	t.Len(got, 1)
+	t.Equal(logrus.FatalLevel, got[0].Level)
+	t.Contains(got[0].Message, "Digest of 1 entry")
+	t.Contains(got[0].Message, "Sample: shutting down")
+}
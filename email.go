@@ -0,0 +1,138 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"crypto/tls"
+	"github.com/ainsleyclark/logger/internal/hooks/email"
+	"github.com/ainsleyclark/logger/types"
+	"time"
+)
+
+// DefaultEmailSubject is the default template used for the
+// subject of emails.
+const DefaultEmailSubject = email.DefaultSubject
+
+// emailConfig is the configuration used to send emails.
+type emailConfig struct {
+	Options   email.Options
+	Report    types.ShouldReportFunc
+	Formatter types.FormatMessageFunc
+}
+
+// enabled returns true if an SMTP server has been set.
+func (c emailConfig) enabled() bool {
+	return c.Options.Host != ""
+}
+
+// emailOptionFunc is a function type that configures an
+// emailConfig instance.
+type emailOptionFunc func(config *emailConfig)
+
+// EmailOptions is the type used to configure the email
+// notifier.
+type EmailOptions struct {
+	optFuncs []emailOptionFunc
+}
+
+// NewEmailOptions creates an EmailOptions instance that
+// sends entries via the SMTP server passed, from the
+// address to the recipients. If the port is zero, 587
+// is used.
+func NewEmailOptions(host string, port int, from string, to ...string) *EmailOptions {
+	op := &EmailOptions{}
+	op.optFuncs = append(op.optFuncs, func(config *emailConfig) {
+		config.Options.Host = host
+		config.Options.Port = port
+		config.Options.From = from
+		config.Options.To = to
+	})
+	return op
+}
+
+// Auth sets the username and password used to authenticate
+// with the SMTP server using PLAIN auth.
+func (op *EmailOptions) Auth(username, password string) *EmailOptions {
+	op.optFuncs = append(op.optFuncs, func(config *emailConfig) {
+		config.Options.Username = username
+		config.Options.Password = password
+	})
+	return op
+}
+
+// Subject sets the text/template used to render the subject,
+// the service, version, prefix, level, message, code and
+// operation are available, for example:
+//
+//	{{ .Level }}: {{ .Code }} in {{ .Operation }}
+//
+// Defaults to DefaultEmailSubject.
+func (op *EmailOptions) Subject(tmpl string) *EmailOptions {
+	op.optFuncs = append(op.optFuncs, func(config *emailConfig) {
+		config.Options.Subject = tmpl
+	})
+	return op
+}
+
+// Timeout sets the maximum amount of time spent sending an
+// email, defaults to 10 seconds.
+func (op *EmailOptions) Timeout(timeout time.Duration) *EmailOptions {
+	op.optFuncs = append(op.optFuncs, func(config *emailConfig) {
+		config.Options.Timeout = timeout
+	})
+	return op
+}
+
+// TLSConfig sets the configuration used to upgrade the
+// connection when the server supports STARTTLS, such as
+// a custom RootCAs pool for an internal relay. The
+// ServerName defaults to the host.
+func (op *EmailOptions) TLSConfig(cfg *tls.Config) *EmailOptions {
+	op.optFuncs = append(op.optFuncs, func(config *emailConfig) {
+		config.Options.TLSConfig = cfg
+	})
+	return op
+}
+
+// Report is the callback function to determine if the
+// entry should be emailed.
+func (op *EmailOptions) Report(fn types.ShouldReportFunc) *EmailOptions {
+	op.optFuncs = append(op.optFuncs, func(config *emailConfig) {
+		config.Report = fn
+	})
+	return op
+}
+
+// Formatter is the function used to create the plain text
+// part of the email, defaults to
+// types.DefaultFormatMessageFn.
+func (op *EmailOptions) Formatter(fn types.FormatMessageFunc) *EmailOptions {
+	op.optFuncs = append(op.optFuncs, func(config *emailConfig) {
+		config.Formatter = fn
+	})
+	return op
+}
+
+// WithEmailNotifier sends entries via email with the email
+// specific options passed. Each email contains a plain
+// text and an HTML part.
+func (op *Options) WithEmailNotifier(opts *EmailOptions) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.email = emailConfig{}
+		for _, optFn := range opts.optFuncs {
+			optFn(&config.email)
+		}
+	})
+	return op
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"crypto/tls"
+	"github.com/ainsleyclark/logger/internal/hooks/email"
+	"github.com/ainsleyclark/logger/types"
+	"time"
+)
+
+func (t *LoggerTestSuite) TestEmailOptions() {
+	tlsConfig := &tls.Config{ServerName: "mail.example.com"}
+	opts := NewOptions().
+		WithEmailNotifier(NewEmailOptions("smtp.example.com", 25, "from@example.com", "a@example.com", "b@example.com").
+			Auth("user", "password").
+			Subject("{{ .Level }}").
+			Timeout(time.Second).
+			TLSConfig(tlsConfig).
+			Report(types.DefaultReportFn).
+			Formatter(types.DefaultFormatMessageFn))
+
+	c := &Config{}
+	for _, optFn := range opts.optFuncs {
+		optFn(c)
+	}
+
+	t.Equal(email.Options{
+		Host:      "smtp.example.com",
+		Port:      25,
+		Username:  "user",
+		Password:  "password",
+		From:      "from@example.com",
+		To:        []string{"a@example.com", "b@example.com"},
+		Subject:   "{{ .Level }}",
+		Timeout:   time.Second,
+		TLSConfig: tlsConfig,
+	}, c.email.Options)
+	t.NotNil(c.email.Report)
+	t.NotNil(c.email.Formatter)
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_AddEmail() {
+	orig := newEmail
+	defer func() {
+		newEmail = orig
+	}()
+
+	var got email.Options
+	newEmail = func(opts email.Options) (*email.Notifier, error) {
+		got = opts
+		return orig(opts)
+	}
+
+	tlsConfig := &tls.Config{ServerName: "mail.example.com"}
+	cfg := &Config{email: emailConfig{Options: email.Options{
+		Host:      "localhost",
+		From:      "from@example.com",
+		To:        []string{"to@example.com"},
+		TLSConfig: tlsConfig,
+	}}}
+	hook := &defaultHook{config: cfg.assignDefaults()}
+	t.NoError(hook.addNotifiers())
+	t.Len(hook.notifiers, 1)
+	t.Equal("email", hook.notifiers[0].Name)
+	t.Same(tlsConfig, got.TLSConfig)
+
+	cfg.email.Options.Subject = "{{ .Level"
+	t.Error(hook.addNotifiers())
+}
@@ -0,0 +1,217 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"github.com/ainsleyclark/errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// FromEnv configures the logger from environment variables
+// that start with the prefix followed by an underscore,
+// if the prefix is empty DefaultPrefix is used. Only
+// variables that are set are applied, and options set
+// after FromEnv take precedence. Invalid values are
+// returned when the logger is created, naming the
+// offending variable. The supported variables are:
+//
+//	LOGGER_SERVICE, LOGGER_VERSION, LOGGER_PREFIX,
+//	LOGGER_DEFAULT_STATUS
+//	LOGGER_LEVEL           trace, debug, info, warn, error, fatal or panic
+//	LOGGER_STDOUT_LEVEL, LOGGER_MONGO_LEVEL, LOGGER_SLACK_LEVEL,
+//	LOGGER_WORKPLACE_LEVEL as above, see SinkLevel
+//	LOGGER_FORMAT          text, json or logfmt
+//	LOGGER_COLOUR          auto, always or never
+//	LOGGER_SLACK_TOKEN, LOGGER_SLACK_CHANNEL, LOGGER_SLACK_WEBHOOK_URL
+//	LOGGER_SLACK_THREAD    duration, such as 1h
+//	LOGGER_WORKPLACE_TOKEN, LOGGER_WORKPLACE_THREAD
+//	LOGGER_MONGO_URI, LOGGER_MONGO_DATABASE, LOGGER_MONGO_COLLECTION
+//	LOGGER_MONGO_QUEUE_SIZE, LOGGER_MONGO_WORKERS  integers
+//	LOGGER_MONGO_OVERFLOW  block, drop_newest or drop_oldest
+//	LOGGER_MONGO_BATCH_SIZE                        integer
+//	LOGGER_MONGO_BATCH_INTERVAL                    duration, such as 5s
+func (op *Options) FromEnv(prefix string) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		if prefix == "" {
+			prefix = DefaultPrefix
+		}
+		e := env{prefix: strings.TrimSuffix(prefix, "_") + "_"}
+		e.apply(config)
+		if e.err != nil && config.optErr == nil {
+			config.optErr = e.err
+		}
+	})
+	return op
+}
+
+// env reads variables for the configuration, the first
+// error that occurs is kept.
+type env struct {
+	prefix string
+	err    error
+}
+
+// apply sets the configuration from the environment.
+func (e *env) apply(c *Config) {
+	e.str("SERVICE", &c.service)
+	e.str("VERSION", &c.version)
+	e.str("PREFIX", &c.prefix)
+	e.str("DEFAULT_STATUS", &c.defaultStatus)
+
+	e.parse("LEVEL", func(v string) error {
+		level, err := parseLevel(v)
+		if err != nil {
+			return err
+		}
+		c.level = &level
+		return nil
+	})
+	for _, sink := range []string{SinkStdout, SinkMongo, SinkSlack, SinkWorkplace} {
+		sink := sink
+		e.parse(strings.ToUpper(sink)+"_LEVEL", func(v string) error {
+			level, err := parseLevel(v)
+			if err != nil {
+				return err
+			}
+			c.setSinkLevel(sink, level, e.prefix+strings.ToUpper(sink)+"_LEVEL")
+			return nil
+		})
+	}
+	e.parse("FORMAT", func(v string) (err error) {
+		c.format, err = parseFormat(v)
+		return err
+	})
+	e.parse("COLOUR", func(v string) (err error) {
+		c.colour, err = parseColour(v)
+		return err
+	})
+
+	e.str("SLACK_TOKEN", &c.slack.Token)
+	e.str("SLACK_CHANNEL", &c.slack.Channel)
+	e.parse("SLACK_WEBHOOK_URL", func(v string) error {
+		if !validURL(v) {
+			return errors.New("must be a valid http or https url")
+		}
+		c.slack.WebhookURL = v
+		return nil
+	})
+	e.duration("SLACK_THREAD", &c.slack.Thread)
+	e.conflict("SLACK_WEBHOOK_URL", "SLACK_TOKEN")
+	e.conflict("SLACK_WEBHOOK_URL", "SLACK_THREAD")
+
+	e.str("WORKPLACE_TOKEN", &c.workplace.Token)
+	e.str("WORKPLACE_THREAD", &c.workplace.Thread)
+	e.require("WORKPLACE_TOKEN", "WORKPLACE_THREAD")
+	e.require("WORKPLACE_THREAD", "WORKPLACE_TOKEN")
+
+	e.str("MONGO_URI", &c.mongo.URI)
+	e.str("MONGO_DATABASE", &c.mongo.Database)
+	e.str("MONGO_COLLECTION", &c.mongo.Name)
+	e.require("MONGO_URI", "MONGO_DATABASE")
+	e.require("MONGO_URI", "MONGO_COLLECTION")
+	e.int("MONGO_QUEUE_SIZE", &c.mongo.QueueSize)
+	e.int("MONGO_WORKERS", &c.mongo.Workers)
+	e.parse("MONGO_OVERFLOW", func(v string) (err error) {
+		c.mongo.Overflow, err = parseOverflow(v)
+		return err
+	})
+	e.int("MONGO_BATCH_SIZE", &c.mongo.BatchSize)
+	e.duration("MONGO_BATCH_INTERVAL", &c.mongo.BatchInterval)
+}
+
+// lookup returns the value of the variable and whether
+// it has been set to a non-empty value.
+func (e *env) lookup(name string) (string, bool) {
+	v, ok := os.LookupEnv(e.prefix + name)
+	v = strings.TrimSpace(v)
+	return v, ok && v != ""
+}
+
+// parse calls fn with the value of the variable if it's
+// set, any error returned is prefixed with the name of
+// the variable.
+func (e *env) parse(name string, fn func(v string) error) {
+	v, ok := e.lookup(name)
+	if !ok || e.err != nil {
+		return
+	}
+	err := fn(v)
+	if err != nil {
+		e.fail("%s%s: invalid value %q, %s", e.prefix, name, v, err.Error())
+	}
+}
+
+// str sets the string to the value of the variable.
+func (e *env) str(name string, s *string) {
+	e.parse(name, func(v string) error {
+		*s = v
+		return nil
+	})
+}
+
+// int sets the integer to the value of the variable.
+func (e *env) int(name string, i *int) {
+	e.parse(name, func(v string) error {
+		n, err := parseInt(v)
+		*i = n
+		return err
+	})
+}
+
+// duration sets the duration to the value of the variable.
+func (e *env) duration(name string, d *time.Duration) {
+	e.parse(name, func(v string) error {
+		n, err := parseDuration(v)
+		*d = n
+		return err
+	})
+}
+
+// require sets an error if the variable is set but none of
+// the others are.
+func (e *env) require(name string, others ...string) {
+	if _, ok := e.lookup(name); !ok || e.err != nil {
+		return
+	}
+	for _, other := range others {
+		if _, ok := e.lookup(other); ok {
+			return
+		}
+	}
+	names := make([]string, len(others))
+	for i, other := range others {
+		names[i] = e.prefix + other
+	}
+	e.fail("%s%s: requires %s to be set", e.prefix, name, strings.Join(names, " or "))
+}
+
+// conflict sets an error if both variables are set.
+func (e *env) conflict(name, other string) {
+	if _, ok := e.lookup(name); !ok || e.err != nil {
+		return
+	}
+	if _, ok := e.lookup(other); ok {
+		e.fail("%s%s: cannot be set with %s%s", e.prefix, name, e.prefix, other)
+	}
+}
+
+// fail sets the error with the message naming the
+// offending variable.
+func (e *env) fail(format string, args ...any) {
+	const op = "Logger.FromEnv"
+	e.err = errors.NewInvalid(fmt.Errorf(format, args...), "Error reading environment variables", op)
+}
@@ -0,0 +1,186 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"github.com/ainsleyclark/errors"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+func (t *LoggerTestSuite) TestOptions_FromEnv() {
+	env := map[string]string{
+		"APP_SERVICE":              "api",
+		"APP_VERSION":              "v1.0.0",
+		"APP_PREFIX":               "API",
+		"APP_DEFAULT_STATUS":       "STATUS",
+		"APP_LEVEL":                "warn",
+		"APP_STDOUT_LEVEL":         "info",
+		"APP_SLACK_LEVEL":          "error",
+		"APP_FORMAT":               "json",
+		"APP_COLOUR":               "never",
+		"APP_SLACK_TOKEN":          "slack-token",
+		"APP_SLACK_CHANNEL":        "#alerts",
+		"APP_SLACK_THREAD":         "1h",
+		"APP_WORKPLACE_TOKEN":      "wp-token",
+		"APP_WORKPLACE_THREAD":     "wp-thread",
+		"APP_MONGO_URI":            "mongodb://localhost:27017",
+		"APP_MONGO_DATABASE":       "logs",
+		"APP_MONGO_COLLECTION":     "api",
+		"APP_MONGO_QUEUE_SIZE":     "100",
+		"APP_MONGO_WORKERS":        "2",
+		"APP_MONGO_OVERFLOW":       "drop_oldest",
+		"APP_MONGO_BATCH_SIZE":     "50",
+		"APP_MONGO_BATCH_INTERVAL": "10s",
+	}
+	for k, v := range env {
+		t.T().Setenv(k, v)
+	}
+
+	c := &Config{}
+	for _, optFn := range NewOptions().FromEnv("APP").optFuncs {
+		optFn(c)
+	}
+
+	t.NoError(c.Validate())
+	t.Equal("api", c.service)
+	t.Equal("v1.0.0", c.version)
+	t.Equal("API", c.prefix)
+	t.Equal("STATUS", c.defaultStatus)
+	t.Equal(logrus.WarnLevel, *c.level)
+	t.Equal(map[string]logrus.Level{SinkStdout: logrus.InfoLevel, SinkSlack: logrus.ErrorLevel}, c.sinkLevels)
+	t.Equal(FormatJSON, c.format)
+	t.Equal(ColourNever, c.colour)
+	t.Equal("slack-token", c.slack.Token)
+	t.Equal("#alerts", c.slack.Channel)
+	t.Equal(time.Hour, c.slack.Thread)
+	t.Equal("wp-token", c.workplace.Token)
+	t.Equal("wp-thread", c.workplace.Thread)
+	t.Equal("mongodb://localhost:27017", c.mongo.URI)
+	t.Equal("logs", c.mongo.Database)
+	t.Equal("api", c.mongo.Name)
+	t.Equal(100, c.mongo.QueueSize)
+	t.Equal(2, c.mongo.Workers)
+	t.Equal(OverflowDropOldest, c.mongo.Overflow)
+	t.Equal(50, c.mongo.BatchSize)
+	t.Equal(time.Second*10, c.mongo.BatchInterval)
+}
+
+func (t *LoggerTestSuite) TestOptions_FromEnvPrecedence() {
+	t.T().Setenv("LOGGER_SERVICE", "env")
+	t.T().Setenv("LOGGER_VERSION", "v1")
+
+	c := &Config{}
+	for _, optFn := range NewOptions().Version("v0").FromEnv("").Service("option").optFuncs {
+		optFn(c)
+	}
+
+	// Options set after FromEnv take precedence, unset
+	// variables are left alone.
+	t.Equal("option", c.service)
+	t.Equal("v1", c.version)
+	t.Nil(c.level)
+}
+
+func (t *LoggerTestSuite) TestOptions_FromEnvErrors() {
+	tt := map[string]struct {
+		input map[string]string
+		want  string
+	}{
+		"Level": {
+			map[string]string{"LOGGER_LEVEL": "loud"},
+			`LOGGER_LEVEL: invalid value "loud"`,
+		},
+		"Sink Level": {
+			map[string]string{"LOGGER_MONGO_LEVEL": "loud"},
+			`LOGGER_MONGO_LEVEL: invalid value "loud"`,
+		},
+		"Format": {
+			map[string]string{"LOGGER_FORMAT": "xml"},
+			`LOGGER_FORMAT: invalid value "xml", must be one of text, json or logfmt`,
+		},
+		"Colour": {
+			map[string]string{"LOGGER_COLOUR": "rainbow"},
+			`LOGGER_COLOUR: invalid value "rainbow"`,
+		},
+		"Slack Thread": {
+			map[string]string{"LOGGER_SLACK_WEBHOOK_URL": "https://hooks.slack.com", "LOGGER_SLACK_THREAD": "soon"},
+			`LOGGER_SLACK_THREAD: invalid value "soon"`,
+		},
+		"Workplace Thread": {
+			map[string]string{"LOGGER_WORKPLACE_TOKEN": "token"},
+			"LOGGER_WORKPLACE_TOKEN: requires LOGGER_WORKPLACE_THREAD to be set",
+		},
+		"Mongo Database": {
+			map[string]string{"LOGGER_MONGO_URI": "mongodb://localhost", "LOGGER_MONGO_COLLECTION": "logs"},
+			"LOGGER_MONGO_URI: requires LOGGER_MONGO_DATABASE to be set",
+		},
+		"Mongo Queue Size": {
+			map[string]string{"LOGGER_MONGO_QUEUE_SIZE": "-1"},
+			`LOGGER_MONGO_QUEUE_SIZE: invalid value "-1", must be a positive integer`,
+		},
+		"Mongo Overflow": {
+			map[string]string{"LOGGER_MONGO_OVERFLOW": "explode"},
+			`LOGGER_MONGO_OVERFLOW: invalid value "explode"`,
+		},
+		"Mongo Batch Interval": {
+			map[string]string{"LOGGER_MONGO_BATCH_INTERVAL": "5"},
+			`LOGGER_MONGO_BATCH_INTERVAL: invalid value "5"`,
+		},
+		"Slack Webhook URL": {
+			map[string]string{"LOGGER_SLACK_WEBHOOK_URL": "hooks.slack.com"},
+			`LOGGER_SLACK_WEBHOOK_URL: invalid value "hooks.slack.com", must be a valid http or https url`,
+		},
+		"Slack Webhook With Token": {
+			map[string]string{"LOGGER_SLACK_WEBHOOK_URL": "https://hooks.slack.com", "LOGGER_SLACK_TOKEN": "token", "LOGGER_SLACK_CHANNEL": "#alerts"},
+			"LOGGER_SLACK_WEBHOOK_URL: cannot be set with LOGGER_SLACK_TOKEN",
+		},
+		"Slack Webhook With Thread": {
+			map[string]string{"LOGGER_SLACK_WEBHOOK_URL": "https://hooks.slack.com", "LOGGER_SLACK_THREAD": "1h"},
+			"LOGGER_SLACK_WEBHOOK_URL: cannot be set with LOGGER_SLACK_THREAD",
+		},
+		"Sink Not Configured": {
+			map[string]string{"LOGGER_SLACK_LEVEL": "error"},
+			"LOGGER_SLACK_LEVEL: level for sink slack is invalid",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			for k, v := range test.input {
+				t.T().Setenv(k, v)
+			}
+			c := &Config{service: "service"}
+			for _, optFn := range NewOptions().FromEnv("LOGGER").optFuncs {
+				optFn(c)
+			}
+			err := c.Validate()
+			t.Error(err)
+			t.Contains(err.Error(), test.want)
+		})
+	}
+}
+
+func (t *LoggerTestSuite) TestOptions_FromEnvErrorCode() {
+	t.T().Setenv("LOGGER_LEVEL", "loud")
+
+	c := &Config{service: "service"}
+	for _, optFn := range NewOptions().FromEnv("LOGGER").optFuncs {
+		optFn(c)
+	}
+
+	err := c.Validate()
+	t.Equal(errors.INVALID, errors.Code(err))
+	t.Equal("Logger.FromEnv", errors.ToError(err).Operation)
+}
@@ -40,6 +40,26 @@ func QuickStart() error {
 	return nil
 }
 
+// Instance godoc
+func Instance() error {
+	l, err := logger.NewLogger(context.TODO(), logger.NewOptions().Service("service"))
+	if err != nil {
+		return err
+	}
+
+	l.Info("Info Entry")
+	l.WithError(errors.NewInternal(errors.New("error"), "message", "op")).Error()
+
+	return nil
+}
+
+// Shutdown godoc
+func Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	return logger.Close(ctx)
+}
+
 // Fields allow you to log out key value pairs to the logger
 // that will appear under data. The simplest way to use the
 // logger is simply the package-level exported logger.
@@ -25,7 +25,6 @@ import (
 	"log"
 	"os"
 	"testing"
-	"time"
 )
 
 func Test_Mongo(t *testing.T) {
@@ -55,5 +54,8 @@ func Test_Mongo(t *testing.T) {
 
 	logger.WithError(errors.NewInternal(errors.New("error"), "message", "op")).Error()
 
-	time.Sleep(time.Second * 1)
+	err = logger.Close(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
 }
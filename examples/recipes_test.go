@@ -18,10 +18,12 @@ import (
 	"github.com/ainsleyclark/errors"
 	"github.com/ainsleyclark/logger"
 	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
 	"os"
+	"time"
 )
 
 // Simple godoc
@@ -42,6 +44,23 @@ func Simple() error {
 	return nil
 }
 
+// JSON godoc
+// Creates a logger that writes entries as JSON.
+func JSON() error {
+	opts := logger.NewOptions().
+		Service("service").
+		Format(logger.FormatJSON)
+
+	err := logger.New(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Hello from Logger!")
+
+	return nil
+}
+
 // WithWorkplace godoc
 // Create a logger with Facebook Workplace integration. A token and a
 // thread are required to send any error code that has been marked
@@ -151,6 +170,34 @@ func WithMongoReport() {
 	// etc
 }
 
+// WithMongoOptions godoc
+// Configure the queue used for writing entries to Mongo.
+func WithMongoOptions(collection *mongo.Collection) {
+	_ = logger.NewOptions().
+		Service("api").
+		WithMongo(logger.NewMongoOptions(collection).
+			Queue(5000, 4).
+			Overflow(logger.OverflowDropOldest).
+			Batch(100, time.Second*5))
+
+	// etc
+}
+
+// WithMongoExpiration godoc
+// Configure how long entries are kept within Mongo.
+func WithMongoExpiration(collection *mongo.Collection) {
+	_ = logger.NewOptions().
+		Service("api").
+		WithMongo(logger.NewMongoOptions(collection).
+			Expiration(logger.ExpirationLevels{
+				logrus.InfoLevel:  time.Hour * 24 * 30,
+				logrus.ErrorLevel: time.Hour * 24 * 365,
+			}).
+			DisableExpiration(logrus.FatalLevel))
+
+	// etc
+}
+
 // KitchenSink godoc
 // Boostrap all Log integrations.
 func KitchenSink() error {
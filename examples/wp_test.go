@@ -22,7 +22,6 @@ import (
 	"log"
 	"os"
 	"testing"
-	"time"
 )
 
 func Test_WP(t *testing.T) {
@@ -52,5 +51,8 @@ func Test_WP(t *testing.T) {
 
 	logger.WithError(errors.NewInternal(errors.New("error"), "message", "op")).Error()
 
-	time.Sleep(time.Second * 1)
+	err = logger.Close(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
 }
@@ -0,0 +1,732 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// fileConfig is the structure of a YAML or JSON
+	// configuration file.
+	fileConfig struct {
+		Service       string            `json:"service" yaml:"service"`
+		Version       string            `json:"version" yaml:"version"`
+		Prefix        string            `json:"prefix" yaml:"prefix"`
+		DefaultStatus string            `json:"default_status" yaml:"default_status"`
+		Level         string            `json:"level" yaml:"level"`
+		Levels        map[string]string `json:"levels" yaml:"levels"`
+		Format        string            `json:"format" yaml:"format"`
+		Colour        string            `json:"colour" yaml:"colour"`
+		Slack         *fileSlack        `json:"slack" yaml:"slack"`
+		Workplace     *fileWorkplace    `json:"workplace" yaml:"workplace"`
+		Teams         *fileWebhook      `json:"teams" yaml:"teams"`
+		Discord       *fileWebhook      `json:"discord" yaml:"discord"`
+		Mongo         *fileMongo        `json:"mongo" yaml:"mongo"`
+		Email         *fileEmail        `json:"email" yaml:"email"`
+		PagerDuty     *filePagerDuty    `json:"pagerduty" yaml:"pagerduty"`
+		Webhooks      []fileNotifier    `json:"webhooks" yaml:"webhooks"`
+		Retry         *fileRetry        `json:"retry" yaml:"retry"`
+		RateLimit     *fileRateLimit    `json:"rate_limit" yaml:"rate_limit"`
+		Digest        *fileDigest       `json:"digest" yaml:"digest"`
+		DeadLetter    *fileDeadLetter   `json:"dead_letter" yaml:"dead_letter"`
+	}
+	// fileSlack is the Slack section of a configuration file.
+	fileSlack struct {
+		Token      string      `json:"token" yaml:"token"`
+		Channel    string      `json:"channel" yaml:"channel"`
+		WebhookURL string      `json:"webhook_url" yaml:"webhook_url"`
+		Thread     string      `json:"thread" yaml:"thread"`
+		Report     *fileReport `json:"report" yaml:"report"`
+	}
+	// fileWorkplace is the Workplace section of a
+	// configuration file.
+	fileWorkplace struct {
+		Token  string      `json:"token" yaml:"token"`
+		Thread string      `json:"thread" yaml:"thread"`
+		Report *fileReport `json:"report" yaml:"report"`
+	}
+	// fileWebhook is the Teams or Discord section of a
+	// configuration file.
+	fileWebhook struct {
+		URL    string      `json:"url" yaml:"url"`
+		Report *fileReport `json:"report" yaml:"report"`
+	}
+	// fileMongo is the Mongo section of a configuration file.
+	fileMongo struct {
+		URI           string      `json:"uri" yaml:"uri"`
+		Database      string      `json:"database" yaml:"database"`
+		Collection    string      `json:"collection" yaml:"collection"`
+		QueueSize     int         `json:"queue_size" yaml:"queue_size"`
+		Workers       int         `json:"workers" yaml:"workers"`
+		Overflow      string      `json:"overflow" yaml:"overflow"`
+		BatchSize     int         `json:"batch_size" yaml:"batch_size"`
+		BatchInterval string      `json:"batch_interval" yaml:"batch_interval"`
+		Report        *fileReport `json:"report" yaml:"report"`
+	}
+	// fileEmail is the email section of a configuration file.
+	fileEmail struct {
+		Host     string      `json:"host" yaml:"host"`
+		Port     int         `json:"port" yaml:"port"`
+		Username string      `json:"username" yaml:"username"`
+		Password string      `json:"password" yaml:"password"`
+		From     string      `json:"from" yaml:"from"`
+		To       []string    `json:"to" yaml:"to"`
+		Subject  string      `json:"subject" yaml:"subject"`
+		Timeout  string      `json:"timeout" yaml:"timeout"`
+		Report   *fileReport `json:"report" yaml:"report"`
+	}
+	// filePagerDuty is the PagerDuty section of a
+	// configuration file.
+	filePagerDuty struct {
+		RoutingKey string      `json:"routing_key" yaml:"routing_key"`
+		BaseURL    string      `json:"base_url" yaml:"base_url"`
+		Source     string      `json:"source" yaml:"source"`
+		Timeout    string      `json:"timeout" yaml:"timeout"`
+		Report     *fileReport `json:"report" yaml:"report"`
+	}
+	// fileNotifier is a generic webhook within the webhooks
+	// section of a configuration file.
+	fileNotifier struct {
+		Name            string            `json:"name" yaml:"name"`
+		URL             string            `json:"url" yaml:"url"`
+		Headers         map[string]string `json:"headers" yaml:"headers"`
+		Template        string            `json:"template" yaml:"template"`
+		Secret          string            `json:"secret" yaml:"secret"`
+		SignatureHeader string            `json:"signature_header" yaml:"signature_header"`
+		Timeout         string            `json:"timeout" yaml:"timeout"`
+		Report          *fileReport       `json:"report" yaml:"report"`
+	}
+	// fileRetry is the retry section of a configuration
+	// file, values that aren't set are taken from the
+	// DefaultRetryPolicy.
+	fileRetry struct {
+		MaxAttempts int      `json:"max_attempts" yaml:"max_attempts"`
+		BaseBackoff string   `json:"base_backoff" yaml:"base_backoff"`
+		MaxBackoff  string   `json:"max_backoff" yaml:"max_backoff"`
+		Jitter      *float64 `json:"jitter" yaml:"jitter"`
+	}
+	// fileRateLimit is the rate limit section of a
+	// configuration file.
+	fileRateLimit struct {
+		Limit  int    `json:"limit" yaml:"limit"`
+		Window string `json:"window" yaml:"window"`
+	}
+	// fileDigest is the digest section of a configuration
+	// file, the report rule determines which entries are
+	// digested.
+	fileDigest struct {
+		Window    string      `json:"window" yaml:"window"`
+		Notifiers []string    `json:"notifiers" yaml:"notifiers"`
+		Report    *fileReport `json:"report" yaml:"report"`
+	}
+	// fileDeadLetter is the dead letter section of a
+	// configuration file.
+	fileDeadLetter struct {
+		Dir     string `json:"dir" yaml:"dir"`
+		MaxSize int64  `json:"max_size" yaml:"max_size"`
+	}
+	// fileReport is the rule used to determine which entries
+	// are sent to a sink. An entry is reported if its
+	// level is within levels and its error code is
+	// within codes, empty lists match every entry.
+	fileReport struct {
+		Levels []string `json:"levels" yaml:"levels"`
+		Codes  []string `json:"codes" yaml:"codes"`
+	}
+)
+
+// FromFile configures the logger from a YAML or JSON file,
+// determined by the file's extension. If interval is
+// greater than zero, the file is checked for changes
+// every interval and the levels and report rules are
+// applied without restarting. Every sink can be set
+// within the file, along with retry, rate_limit,
+// digest and dead_letter, see the README for the
+// full format. If a changed file fails validation,
+// the error is passed to OnError and the previous
+// configuration is kept. Other changes, such as sink
+// credentials, are applied on the next start. Options
+// set after FromFile take precedence, except for the
+// report rules within the file which are applied to
+// every sink once all options have been set. A
+// sink's report function is only used when the file
+// has no rule for it.
+func (op *Options) FromFile(path string, interval time.Duration) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		src := &fileSource{
+			path:     path,
+			interval: interval,
+		}
+		err := src.load(config)
+		if err != nil && config.optErr == nil {
+			config.optErr = err
+		}
+		config.file = src
+	})
+	return op
+}
+
+// fileSource is a configuration file that is watched for
+// changes.
+type fileSource struct {
+	path     string
+	interval time.Duration
+	// The rules within the file when it was loaded, and the
+	// rules bound to each sink that are replaced when
+	// the file is reloaded.
+	initial map[string]*ruleSet
+	rules   map[string]*reportRule
+	mtx     sync.Mutex
+	modTime time.Time
+	size    int64
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// load reads the file and applies it to the configuration,
+// the report rules are kept until they are bound to the
+// sinks.
+func (s *fileSource) load(c *Config) error {
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	rules, err := f.apply(c, s.path)
+	if err != nil {
+		return err
+	}
+	s.initial = rules
+	return nil
+}
+
+// bind replaces the report functions of the sinks with
+// rules that can be changed when the file is reloaded.
+// It's called once all options have been applied, so
+// sinks configured after FromFile still use the rules
+// within the file. The report function of each sink is
+// used when the file has no rule for it.
+func (s *fileSource) bind(c *Config) {
+	if s.rules != nil {
+		return
+	}
+	s.rules = make(map[string]*reportRule)
+	reports := map[string]*types.ShouldReportFunc{
+		"slack":     &c.slack.Report,
+		"workplace": &c.workplace.Report,
+		"teams":     &c.teams.Report,
+		"discord":   &c.discord.Report,
+		"mongo":     &c.mongo.Report,
+		"email":     &c.email.Report,
+		"pagerduty": &c.pagerDuty.Report,
+		"digest":    &c.digest.Report,
+	}
+	for i := range c.webhooks {
+		reports[webhookRule(c.webhooks[i].name())] = &c.webhooks[i].Report
+	}
+	for name, report := range reports {
+		r := &reportRule{fallback: *report}
+		r.set(s.initial[name])
+		s.rules[name] = r
+		*report = r.Report
+	}
+}
+
+// webhookRule returns the key of the report rule for the
+// webhook with the name passed.
+func webhookRule(name string) string {
+	return "webhooks." + name
+}
+
+// reload reads the file and applies the level and report
+// rules to the logger if it passes validation.
+func (s *fileSource) reload(l *Logger) error {
+	const op = "Logger.Reload"
+	f, err := s.read()
+	if err != nil {
+		return errors.NewInvalid(err, "Error reloading config file, keeping the previous config", op)
+	}
+	next := *l.config
+	rules, err := f.apply(&next, s.path)
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		return errors.NewInvalid(err, "Error reloading config file, keeping the previous config", op)
+	}
+	// Only levels within the file are applied so levels
+	// changed at runtime are kept.
+	if f.Level != "" {
+		l.SetLevel(*next.level)
+	}
+	for sink := range f.Levels {
+		l.levels.Set(sink, next.sinkLevels[sink])
+	}
+	for name, r := range s.rules {
+		r.set(rules[name])
+	}
+	return nil
+}
+
+// read parses the file based on its extension and stores
+// the modification time.
+func (s *fileSource) read() (*fileConfig, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mtx.Lock()
+	s.modTime, s.size = info.ModTime(), info.Size()
+	s.mtx.Unlock()
+
+	f := &fileConfig{}
+	switch strings.ToLower(filepath.Ext(s.path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(buf))
+		dec.KnownFields(true)
+		err = dec.Decode(f)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(buf))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(f)
+	default:
+		return nil, fmt.Errorf("%s: unsupported file extension, must be .yaml, .yml or .json", s.path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", s.path, err.Error())
+	}
+	return f, nil
+}
+
+// fileStat is the modification time and size of a file
+// used to detect changes.
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// stat returns the current fileStat of the file.
+func (s *fileSource) stat() (fileStat, bool) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fileStat{}, false
+	}
+	return fileStat{modTime: info.ModTime(), size: info.Size()}, true
+}
+
+// changed determines if the file has been modified since
+// it was last read.
+func (s *fileSource) changed(st fileStat) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return !st.modTime.Equal(s.modTime) || st.size != s.size
+}
+
+// watch checks the file for changes every interval until
+// the source is closed. A change is only applied once
+// the file has stayed the same for an interval, so a
+// file that is still being written isn't read.
+func (s *fileSource) watch(l *Logger) {
+	if s.interval <= 0 {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		var (
+			pending   fileStat
+			pendingAt time.Time
+		)
+		for {
+			select {
+			case <-ticker.C:
+				st, ok := s.stat()
+				if !ok || !s.changed(st) {
+					continue
+				}
+				if st != pending {
+					pending, pendingAt = st, time.Now()
+					continue
+				}
+				if time.Since(pendingAt) < s.interval {
+					continue
+				}
+				err := s.reload(l)
+				if err != nil && l.hook != nil {
+					l.hook.logError(err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// close stops watching the file.
+func (s *fileSource) close() {
+	if s.stop == nil {
+		return
+	}
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+}
+
+// apply sets the values within the file on the
+// configuration and returns the report rules for
+// each sink.
+func (f *fileConfig) apply(c *Config, path string) (map[string]*ruleSet, error) {
+	p := fileParser{path: path}
+	rules := make(map[string]*ruleSet)
+
+	p.str(f.Service, &c.service)
+	p.str(f.Version, &c.version)
+	p.str(f.Prefix, &c.prefix)
+	p.str(f.DefaultStatus, &c.defaultStatus)
+	p.parse("level", f.Level, func(v string) error {
+		level, err := parseLevel(v)
+		if err != nil {
+			return err
+		}
+		c.level = &level
+		return nil
+	})
+	for sink, v := range f.Levels {
+		sink := sink
+		p.parse("levels."+sink, v, func(v string) error {
+			level, err := parseLevel(v)
+			if err != nil {
+				return err
+			}
+			c.setSinkLevel(sink, level, path+": levels."+sink)
+			return nil
+		})
+	}
+	p.parse("format", f.Format, func(v string) (err error) {
+		c.format, err = parseFormat(v)
+		return err
+	})
+	p.parse("colour", f.Colour, func(v string) (err error) {
+		c.colour, err = parseColour(v)
+		return err
+	})
+
+	if f.Slack != nil {
+		p.str(f.Slack.Token, &c.slack.Token)
+		p.str(f.Slack.Channel, &c.slack.Channel)
+		p.str(f.Slack.WebhookURL, &c.slack.WebhookURL)
+		p.parse("slack.thread", f.Slack.Thread, func(v string) (err error) {
+			c.slack.Thread, err = parseDuration(v)
+			return err
+		})
+		rules["slack"] = p.report("slack.report", f.Slack.Report)
+	}
+
+	if f.Workplace != nil {
+		p.str(f.Workplace.Token, &c.workplace.Token)
+		p.str(f.Workplace.Thread, &c.workplace.Thread)
+		rules["workplace"] = p.report("workplace.report", f.Workplace.Report)
+	}
+
+	if f.Teams != nil {
+		p.str(f.Teams.URL, &c.teams.URL)
+		rules["teams"] = p.report("teams.report", f.Teams.Report)
+	}
+
+	if f.Discord != nil {
+		p.str(f.Discord.URL, &c.discord.URL)
+		rules["discord"] = p.report("discord.report", f.Discord.Report)
+	}
+
+	if f.Mongo != nil {
+		p.str(f.Mongo.URI, &c.mongo.URI)
+		p.str(f.Mongo.Database, &c.mongo.Database)
+		p.str(f.Mongo.Collection, &c.mongo.Name)
+		if f.Mongo.QueueSize != 0 {
+			c.mongo.QueueSize = f.Mongo.QueueSize
+		}
+		if f.Mongo.Workers != 0 {
+			c.mongo.Workers = f.Mongo.Workers
+		}
+		if f.Mongo.BatchSize != 0 {
+			c.mongo.BatchSize = f.Mongo.BatchSize
+		}
+		p.parse("mongo.overflow", f.Mongo.Overflow, func(v string) (err error) {
+			c.mongo.Overflow, err = parseOverflow(v)
+			return err
+		})
+		p.parse("mongo.batch_interval", f.Mongo.BatchInterval, func(v string) (err error) {
+			c.mongo.BatchInterval, err = parseDuration(v)
+			return err
+		})
+		rules["mongo"] = p.report("mongo.report", f.Mongo.Report)
+	}
+
+	if f.Email != nil {
+		p.str(f.Email.Host, &c.email.Options.Host)
+		if f.Email.Port != 0 {
+			c.email.Options.Port = f.Email.Port
+		}
+		p.str(f.Email.Username, &c.email.Options.Username)
+		p.str(f.Email.Password, &c.email.Options.Password)
+		p.str(f.Email.From, &c.email.Options.From)
+		if len(f.Email.To) > 0 {
+			c.email.Options.To = f.Email.To
+		}
+		p.str(f.Email.Subject, &c.email.Options.Subject)
+		p.parse("email.timeout", f.Email.Timeout, func(v string) (err error) {
+			c.email.Options.Timeout, err = parseDuration(v)
+			return err
+		})
+		rules["email"] = p.report("email.report", f.Email.Report)
+	}
+
+	if f.PagerDuty != nil {
+		p.str(f.PagerDuty.RoutingKey, &c.pagerDuty.Options.RoutingKey)
+		p.str(f.PagerDuty.BaseURL, &c.pagerDuty.Options.BaseURL)
+		p.str(f.PagerDuty.Source, &c.pagerDuty.Options.Source)
+		p.parse("pagerduty.timeout", f.PagerDuty.Timeout, func(v string) (err error) {
+			c.pagerDuty.Options.Timeout, err = parseDuration(v)
+			return err
+		})
+		rules["pagerduty"] = p.report("pagerduty.report", f.PagerDuty.Report)
+	}
+
+	if len(f.Webhooks) > 0 {
+		p.webhooks(c, f.Webhooks, rules)
+	}
+
+	if f.Retry != nil {
+		if c.retry == (RetryPolicy{}) {
+			c.retry = DefaultRetryPolicy()
+		}
+		if f.Retry.MaxAttempts != 0 {
+			c.retry.MaxAttempts = f.Retry.MaxAttempts
+		}
+		p.parse("retry.base_backoff", f.Retry.BaseBackoff, func(v string) (err error) {
+			c.retry.BaseBackoff, err = parseDuration(v)
+			return err
+		})
+		p.parse("retry.max_backoff", f.Retry.MaxBackoff, func(v string) (err error) {
+			c.retry.MaxBackoff, err = parseDuration(v)
+			return err
+		})
+		if f.Retry.Jitter != nil {
+			c.retry.Jitter = *f.Retry.Jitter
+		}
+	}
+
+	if f.RateLimit != nil {
+		if f.RateLimit.Limit != 0 {
+			c.rateLimit.Limit = f.RateLimit.Limit
+		}
+		p.parse("rate_limit.window", f.RateLimit.Window, func(v string) (err error) {
+			c.rateLimit.Window, err = parseDuration(v)
+			return err
+		})
+	}
+
+	if f.Digest != nil {
+		p.parse("digest.window", f.Digest.Window, func(v string) (err error) {
+			c.digest.Window, err = parseDuration(v)
+			return err
+		})
+		if len(f.Digest.Notifiers) > 0 {
+			c.digest.Notifiers = f.Digest.Notifiers
+		}
+		rules["digest"] = p.report("digest.report", f.Digest.Report)
+	}
+
+	if f.DeadLetter != nil {
+		p.str(f.DeadLetter.Dir, &c.deadLetter.Dir)
+		if f.DeadLetter.MaxSize != 0 {
+			c.deadLetter.MaxSize = f.DeadLetter.MaxSize
+		}
+	}
+
+	return rules, p.err
+}
+
+// webhooks sets the generic webhooks within the file on
+// the configuration. Webhooks are matched on their name
+// so that a webhook that has already been configured
+// is updated rather than added again. The slice is
+// copied as it may be shared with another Config.
+func (p *fileParser) webhooks(c *Config, webhooks []fileNotifier, rules map[string]*ruleSet) {
+	configs := make([]webhookConfig, len(c.webhooks), len(c.webhooks)+len(webhooks))
+	copy(configs, c.webhooks)
+	seen := make(map[string]bool, len(webhooks))
+	for i, wh := range webhooks {
+		field := fmt.Sprintf("webhooks[%d]", i)
+		cfg := webhookConfig{Name: wh.Name}
+		p.parse(field+".name", cfg.name(), func(v string) error {
+			if seen[v] {
+				return errors.New("must be unique")
+			}
+			seen[v] = true
+			return nil
+		})
+		index := -1
+		for j := range configs {
+			if configs[j].name() == cfg.name() {
+				index = j
+				cfg = configs[j]
+			}
+		}
+		p.str(wh.URL, &cfg.Options.URL)
+		if len(wh.Headers) > 0 {
+			cfg.Options.Headers = wh.Headers
+		}
+		p.str(wh.Template, &cfg.Options.Template)
+		p.str(wh.Secret, &cfg.Options.Secret)
+		p.str(wh.SignatureHeader, &cfg.Options.SignatureHeader)
+		p.parse(field+".timeout", wh.Timeout, func(v string) (err error) {
+			cfg.Options.Timeout, err = parseDuration(v)
+			return err
+		})
+		rules[webhookRule(cfg.name())] = p.report(field+".report", wh.Report)
+		if index == -1 {
+			configs = append(configs, cfg)
+			continue
+		}
+		configs[index] = cfg
+	}
+	c.webhooks = configs
+}
+
+// fileParser parses the values within a configuration
+// file, the first error that occurs is kept.
+type fileParser struct {
+	path string
+	err  error
+}
+
+// parse calls fn with the value if it's set, any error
+// returned is prefixed with the path and field.
+func (p *fileParser) parse(field, v string, fn func(v string) error) {
+	if v == "" || p.err != nil {
+		return
+	}
+	err := fn(v)
+	if err != nil {
+		p.err = fmt.Errorf("%s: %s: invalid value %q, %s", p.path, field, v, err.Error())
+	}
+}
+
+// str sets the string to the value if it's set.
+func (p *fileParser) str(v string, s *string) {
+	if v != "" {
+		*s = v
+	}
+}
+
+// report parses the report rule for a sink.
+func (p *fileParser) report(field string, r *fileReport) *ruleSet {
+	if r == nil {
+		return nil
+	}
+	rs := &ruleSet{codes: r.Codes}
+	for _, v := range r.Levels {
+		p.parse(field+".levels", v, func(v string) error {
+			level, err := parseLevel(v)
+			rs.levels = append(rs.levels, level)
+			return err
+		})
+	}
+	return rs
+}
+
+// ruleSet is a parsed fileReport.
+type ruleSet struct {
+	levels []logrus.Level
+	codes  []string
+}
+
+// Report determines if the entry matches the rule.
+func (r *ruleSet) Report(e types.Entry) bool {
+	if len(r.levels) > 0 && !containsLevel(r.levels, e.Level) {
+		return false
+	}
+	if len(r.codes) == 0 {
+		return true
+	}
+	err := e.Error()
+	if err == nil {
+		return false
+	}
+	for _, code := range r.codes {
+		if strings.EqualFold(code, err.Code) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsLevel determines if the level is within the
+// slice.
+func containsLevel(levels []logrus.Level, level logrus.Level) bool {
+	for _, l := range levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// reportRule is a report function for a sink that can be
+// changed at runtime, if no rule has been set from the
+// file the fallback is used.
+type reportRule struct {
+	fallback types.ShouldReportFunc
+	rule     atomic.Value
+}
+
+// set replaces the rule, nil removes it.
+func (r *reportRule) set(rs *ruleSet) {
+	r.rule.Store(rs)
+}
+
+// Report determines if the entry should be sent to the
+// sink.
+func (r *reportRule) Report(e types.Entry) bool {
+	if rs, _ := r.rule.Load().(*ruleSet); rs != nil {
+		return rs.Report(e)
+	}
+	if r.fallback != nil {
+		return r.fallback(e)
+	}
+	return true
+}
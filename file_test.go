@@ -0,0 +1,498 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// writeFile replaces a configuration file by renaming a
+// temporary file over it, the modification time is moved
+// forward so that changes are always picked up.
+func (t *LoggerTestSuite) writeFile(path, content string) {
+	mod := time.Now().Add(time.Second)
+	if info, err := os.Stat(path); err == nil && !info.ModTime().Before(mod) {
+		mod = info.ModTime().Add(time.Second)
+	}
+	tmp := path + ".tmp"
+	t.NoError(os.WriteFile(tmp, []byte(content), 0o600))
+	t.NoError(os.Chtimes(tmp, mod, mod))
+	t.NoError(os.Rename(tmp, path))
+}
+
+func (t *LoggerTestSuite) TestOptions_FromFile() {
+	yml := `
+service: api
+version: v1.0.0
+prefix: API
+default_status: STATUS
+level: warn
+levels:
+  stdout: info
+  slack: error
+format: json
+colour: never
+slack:
+  token: slack-token
+  channel: "#alerts"
+  thread: 1h
+  report:
+    levels: [error, fatal]
+    codes: [internal]
+workplace:
+  token: wp-token
+  thread: wp-thread
+teams:
+  url: https://teams.com
+discord:
+  url: https://discord.com
+mongo:
+  uri: mongodb://localhost:27017
+  database: logs
+  collection: api
+  queue_size: 100
+  workers: 2
+  overflow: drop_oldest
+  batch_size: 50
+  batch_interval: 10s
+email:
+  host: smtp.example.com
+  port: 25
+  username: user
+  password: pass
+  from: logger@example.com
+  to: [ops@example.com]
+  subject: "{{ .Level }}"
+  timeout: 5s
+pagerduty:
+  routing_key: routing-key
+  base_url: https://events.example.com
+  source: api-1
+  timeout: 5s
+webhooks:
+  - name: incidents
+    url: https://example.com/hook
+    headers:
+      Authorization: Bearer token
+    template: '{"text": {{ json .Message }}}'
+    secret: secret
+    signature_header: X-Signature
+    timeout: 5s
+    report:
+      codes: [internal]
+  - url: https://example.com/default
+retry:
+  max_attempts: 5
+  base_backoff: 1s
+  jitter: 0
+rate_limit:
+  limit: 10
+  window: 1m
+digest:
+  window: 15m
+  notifiers: [slack]
+  report:
+    levels: [warn]
+dead_letter:
+  dir: /var/lib/logger
+  max_size: 1024
+`
+	json := `{
+	"service": "api",
+	"level": "warn",
+	"format": "json",
+	"colour": "never",
+	"slack": {"webhook_url": "https://hooks.slack.com/services/a", "report": {"levels": ["error"]}}
+}`
+
+	tt := map[string]struct {
+		file    string
+		content string
+		test    func(c *Config)
+	}{
+		"YAML": {
+			"config.yaml",
+			yml,
+			func(c *Config) {
+				t.Equal("api", c.service)
+				t.Equal("v1.0.0", c.version)
+				t.Equal("API", c.prefix)
+				t.Equal("STATUS", c.defaultStatus)
+				t.Equal(logrus.WarnLevel, *c.level)
+				t.Equal(map[string]logrus.Level{SinkStdout: logrus.InfoLevel, SinkSlack: logrus.ErrorLevel}, c.sinkLevels)
+				t.Equal(FormatJSON, c.format)
+				t.Equal(ColourNever, c.colour)
+				t.Equal("slack-token", c.slack.Token)
+				t.Equal("#alerts", c.slack.Channel)
+				t.Equal(time.Hour, c.slack.Thread)
+				t.Equal("wp-token", c.workplace.Token)
+				t.Equal("wp-thread", c.workplace.Thread)
+				t.Equal("https://teams.com", c.teams.URL)
+				t.Equal("https://discord.com", c.discord.URL)
+				t.Equal("mongodb://localhost:27017", c.mongo.URI)
+				t.Equal("logs", c.mongo.Database)
+				t.Equal("api", c.mongo.Name)
+				t.Equal(100, c.mongo.QueueSize)
+				t.Equal(2, c.mongo.Workers)
+				t.Equal(OverflowDropOldest, c.mongo.Overflow)
+				t.Equal(50, c.mongo.BatchSize)
+				t.Equal(time.Second*10, c.mongo.BatchInterval)
+				t.Equal("smtp.example.com", c.email.Options.Host)
+				t.Equal(25, c.email.Options.Port)
+				t.Equal("user", c.email.Options.Username)
+				t.Equal("pass", c.email.Options.Password)
+				t.Equal("logger@example.com", c.email.Options.From)
+				t.Equal([]string{"ops@example.com"}, c.email.Options.To)
+				t.Equal("{{ .Level }}", c.email.Options.Subject)
+				t.Equal(time.Second*5, c.email.Options.Timeout)
+				t.Equal("routing-key", c.pagerDuty.Options.RoutingKey)
+				t.Equal("https://events.example.com", c.pagerDuty.Options.BaseURL)
+				t.Equal("api-1", c.pagerDuty.Options.Source)
+				t.Equal(time.Second*5, c.pagerDuty.Options.Timeout)
+				t.Len(c.webhooks, 2)
+				t.Equal("incidents", c.webhooks[0].name())
+				t.Equal("https://example.com/hook", c.webhooks[0].Options.URL)
+				t.Equal(map[string]string{"Authorization": "Bearer token"}, c.webhooks[0].Options.Headers)
+				t.Equal(`{"text": {{ json .Message }}}`, c.webhooks[0].Options.Template)
+				t.Equal("secret", c.webhooks[0].Options.Secret)
+				t.Equal("X-Signature", c.webhooks[0].Options.SignatureHeader)
+				t.Equal(time.Second*5, c.webhooks[0].Options.Timeout)
+				t.Equal(SinkWebhook, c.webhooks[1].name())
+				t.Equal("https://example.com/default", c.webhooks[1].Options.URL)
+				t.Equal(RetryPolicy{MaxAttempts: 5, BaseBackoff: time.Second, MaxBackoff: DefaultRetryPolicy().MaxBackoff}, c.retry)
+				t.Equal(rateLimitConfig{Limit: 10, Window: time.Minute}, c.rateLimit)
+				t.Equal(time.Minute*15, c.digest.Window)
+				t.Equal([]string{SinkSlack}, c.digest.Notifiers)
+				t.Equal("/var/lib/logger", c.deadLetter.Dir)
+				t.Equal(int64(1024), c.deadLetter.MaxSize)
+
+				internal := types.Entry{Level: logrus.ErrorLevel, Data: logrus.Fields{types.ErrorKey: errors.NewInternal(errors.New("error"), "message", "op")}}
+				invalid := types.Entry{Level: logrus.ErrorLevel, Data: logrus.Fields{types.ErrorKey: errors.NewInvalid(errors.New("error"), "message", "op")}}
+				t.True(c.slack.Report(internal))
+				t.False(c.slack.Report(invalid))
+				t.True(c.workplace.Report(invalid))
+				t.True(c.webhooks[0].Report(internal))
+				t.False(c.webhooks[0].Report(invalid))
+				t.True(c.webhooks[1].Report(invalid))
+				t.True(c.pagerDuty.Report(internal))
+				t.False(c.pagerDuty.Report(invalid))
+				t.True(c.digest.Report(types.Entry{Level: logrus.WarnLevel}))
+				t.False(c.digest.Report(types.Entry{Level: logrus.ErrorLevel}))
+			},
+		},
+		"JSON": {
+			"config.json",
+			json,
+			func(c *Config) {
+				t.Equal("api", c.service)
+				t.Equal(logrus.WarnLevel, *c.level)
+				t.Equal("https://hooks.slack.com/services/a", c.slack.WebhookURL)
+				t.True(c.slack.Report(types.Entry{Level: logrus.ErrorLevel}))
+				t.False(c.slack.Report(types.Entry{Level: logrus.WarnLevel}))
+			},
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			path := filepath.Join(t.T().TempDir(), test.file)
+			t.writeFile(path, test.content)
+			c := &Config{}
+			for _, optFn := range NewOptions().FromFile(path, 0).optFuncs {
+				optFn(c)
+			}
+			t.NoError(c.Validate())
+			test.test(c.assignDefaults())
+		})
+	}
+}
+
+func (t *LoggerTestSuite) TestOptions_FromFileErrors() {
+	tt := map[string]struct {
+		file    string
+		content string
+		want    string
+	}{
+		"Not Found": {
+			"",
+			"",
+			"no such file or directory",
+		},
+		"Extension": {
+			"config.toml",
+			"service = 'api'",
+			"unsupported file extension",
+		},
+		"Unknown Field": {
+			"config.yaml",
+			"service: api\nlevle: info",
+			"field levle not found",
+		},
+		"Unknown JSON Field": {
+			"config.json",
+			`{"service": "api", "levle": "info"}`,
+			`unknown field "levle"`,
+		},
+		"Level": {
+			"config.yaml",
+			"level: loud",
+			`level: invalid value "loud"`,
+		},
+		"Sink Level": {
+			"config.yaml",
+			"levels:\n  mongo: loud",
+			`levels.mongo: invalid value "loud"`,
+		},
+		"Unknown Sink": {
+			"config.yaml",
+			"levels:\n  slak: error",
+			"config.yaml: levels.slak: level for sink slak is invalid, the sink must be one of mongo, stdout",
+		},
+		"Report Level": {
+			"config.yaml",
+			"slack:\n  report:\n    levels: [loud]",
+			`slack.report.levels: invalid value "loud"`,
+		},
+		"Mongo Batch Interval": {
+			"config.yaml",
+			"mongo:\n  batch_interval: 5",
+			`mongo.batch_interval: invalid value "5"`,
+		},
+		"Email Timeout": {
+			"config.yaml",
+			"email:\n  timeout: soon",
+			`email.timeout: invalid value "soon"`,
+		},
+		"Webhook Name": {
+			"config.yaml",
+			"webhooks:\n  - url: https://example.com/a\n  - url: https://example.com/b",
+			`webhooks[1].name: invalid value "webhook", must be unique`,
+		},
+		"Webhook Report": {
+			"config.yaml",
+			"webhooks:\n  - url: https://example.com\n    report:\n      levels: [loud]",
+			`webhooks[0].report.levels: invalid value "loud"`,
+		},
+		"Retry Backoff": {
+			"config.yaml",
+			"retry:\n  max_backoff: -1s",
+			`retry.max_backoff: invalid value "-1s"`,
+		},
+		"Rate Limit Window": {
+			"config.yaml",
+			"rate_limit:\n  window: 5",
+			`rate_limit.window: invalid value "5"`,
+		},
+		"Digest Window": {
+			"config.yaml",
+			"digest:\n  window: 5",
+			`digest.window: invalid value "5"`,
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			path := filepath.Join(t.T().TempDir(), "missing.yaml")
+			if test.file != "" {
+				path = filepath.Join(t.T().TempDir(), test.file)
+				t.writeFile(path, test.content)
+			}
+			c := &Config{service: "service"}
+			for _, optFn := range NewOptions().FromFile(path, 0).optFuncs {
+				optFn(c)
+			}
+			err := c.Validate()
+			t.Error(err)
+			t.Contains(err.Error(), test.want)
+		})
+	}
+}
+
+func (t *LoggerTestSuite) TestOptions_FromFileReload() {
+	var (
+		mtx  sync.Mutex
+		errs []error
+	)
+	path := filepath.Join(t.T().TempDir(), "config.yaml")
+	t.writeFile(path, "service: api\nlevel: info\nslack:\n  webhook_url: https://hooks.slack.com/services/a\n  report:\n    levels: [error]\n")
+
+	l, err := NewLogger(context.Background(), NewOptions().
+		FromFile(path, time.Millisecond*5).
+		OnError(func(err error) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			errs = append(errs, err)
+		}))
+	t.NoError(err)
+	defer func() {
+		t.NoError(l.Close(context.Background()))
+	}()
+
+	warn := types.Entry{Level: logrus.WarnLevel}
+	t.Equal(logrus.InfoLevel, l.GetLevel())
+	t.False(l.config.slack.Report(warn))
+
+	t.Run("Changed", func() {
+		t.writeFile(path, "service: api\nlevel: debug\nlevels:\n  slack: error\nslack:\n  report:\n    levels: [warn, error]\n")
+		t.Eventually(func() bool {
+			return l.GetLevel() == logrus.DebugLevel
+		}, time.Second*5, time.Millisecond*5)
+		t.True(l.config.slack.Report(warn))
+		t.False(l.levels.Enabled(SinkSlack, logrus.WarnLevel))
+	})
+
+	t.Run("Removed Rule", func() {
+		t.writeFile(path, "service: api\nlevel: info\n")
+		t.Eventually(func() bool {
+			return l.GetLevel() == logrus.InfoLevel
+		}, time.Second*5, time.Millisecond*5)
+		t.True(l.config.slack.Report(types.Entry{Level: logrus.TraceLevel}))
+	})
+
+	t.Run("Invalid", func() {
+		t.writeFile(path, "service: api\nlevel: loud\nslack:\n  report:\n    levels: [panic]\n")
+		t.Eventually(func() bool {
+			mtx.Lock()
+			defer mtx.Unlock()
+			return len(errs) == 1
+		}, time.Second*5, time.Millisecond*5)
+		mtx.Lock()
+		t.Contains(errs[0].Error(), `level: invalid value "loud"`)
+		mtx.Unlock()
+		t.Equal(logrus.InfoLevel, l.GetLevel())
+		t.True(l.config.slack.Report(warn))
+	})
+
+	t.Run("Validation", func() {
+		t.writeFile(path, "service: api\nlevel: warn\nworkplace:\n  token: token\n")
+		t.Eventually(func() bool {
+			mtx.Lock()
+			defer mtx.Unlock()
+			return len(errs) == 2
+		}, time.Second*5, time.Millisecond*5)
+		mtx.Lock()
+		t.Contains(errs[1].Error(), "workplace thread cannot be nil")
+		mtx.Unlock()
+		t.Equal(logrus.InfoLevel, l.GetLevel())
+	})
+}
+
+func (t *LoggerTestSuite) TestOptions_FromFileReloadWebhook() {
+	path := filepath.Join(t.T().TempDir(), "config.yaml")
+	t.writeFile(path, "service: api\nwebhooks:\n  - name: incidents\n    url: https://example.com\n    report:\n      levels: [error]\n")
+
+	l, err := NewLogger(context.Background(), NewOptions().FromFile(path, time.Millisecond*5))
+	t.NoError(err)
+	defer func() {
+		t.NoError(l.Close(context.Background()))
+	}()
+
+	warn := types.Entry{Level: logrus.WarnLevel}
+	t.Len(l.config.webhooks, 1)
+	t.False(l.config.webhooks[0].Report(warn))
+
+	t.writeFile(path, "service: api\nlevels:\n  incidents: debug\nwebhooks:\n  - name: incidents\n    url: https://example.com\n    report:\n      levels: [warn, error]\n")
+	t.Eventually(func() bool {
+		return l.config.webhooks[0].Report(warn)
+	}, time.Second*5, time.Millisecond*5)
+	t.False(l.levels.Enabled("incidents", logrus.TraceLevel))
+}
+
+func (t *LoggerTestSuite) TestOptions_FromFileReloadAfterOptions() {
+	path := filepath.Join(t.T().TempDir(), "config.yaml")
+	t.writeFile(path, "service: api\nslack:\n  report:\n    levels: [error]\n")
+
+	// Slack is configured after the file, the rule within the
+	// file still applies and is reloaded.
+	l, err := NewLogger(context.Background(), NewOptions().
+		FromFile(path, time.Millisecond*5).
+		WithSlackNotifier("token", "#alerts", nil, nil))
+	t.NoError(err)
+	defer func() {
+		t.NoError(l.Close(context.Background()))
+	}()
+
+	warn := types.Entry{Level: logrus.WarnLevel}
+	t.False(l.config.slack.Report(warn))
+	t.True(l.config.slack.Report(types.Entry{Level: logrus.ErrorLevel}))
+
+	t.writeFile(path, "service: api\nslack:\n  report:\n    levels: [warn, error]\n")
+	t.Eventually(func() bool {
+		return l.config.slack.Report(warn)
+	}, time.Second*5, time.Millisecond*5)
+}
+
+func (t *LoggerTestSuite) TestRuleSet_Report() {
+	internal := types.Entry{Level: logrus.ErrorLevel, Data: logrus.Fields{types.ErrorKey: errors.NewInternal(errors.New("error"), "message", "op")}}
+
+	tt := map[string]struct {
+		rule  ruleSet
+		input types.Entry
+		want  bool
+	}{
+		"Empty": {
+			ruleSet{},
+			types.Entry{Level: logrus.InfoLevel},
+			true,
+		},
+		"Level": {
+			ruleSet{levels: []logrus.Level{logrus.ErrorLevel}},
+			types.Entry{Level: logrus.InfoLevel},
+			false,
+		},
+		"Code": {
+			ruleSet{codes: []string{"INTERNAL"}},
+			internal,
+			true,
+		},
+		"No Error": {
+			ruleSet{codes: []string{errors.INTERNAL}},
+			types.Entry{Level: logrus.ErrorLevel},
+			false,
+		},
+		"Level And Code": {
+			ruleSet{levels: []logrus.Level{logrus.WarnLevel}, codes: []string{errors.INTERNAL}},
+			internal,
+			false,
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			t.Equal(test.want, test.rule.Report(test.input))
+		})
+	}
+}
+
+func (t *LoggerTestSuite) TestReportRule() {
+	r := &reportRule{}
+	r.set(nil)
+	t.True(r.Report(types.Entry{}))
+
+	r = &reportRule{fallback: func(e types.Entry) bool {
+		return false
+	}}
+	r.set(nil)
+	t.False(r.Report(types.Entry{}))
+
+	r.set(&ruleSet{})
+	t.True(r.Report(types.Entry{}))
+}
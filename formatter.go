@@ -21,38 +21,82 @@ import (
 	"github.com/sirupsen/logrus"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
+// newFormatter returns the logrus.Formatter for the
+// output format set on the configuration.
+func newFormatter(cfg *Config) logrus.Formatter {
+	switch cfg.format {
+	case FormatJSON:
+		return &jsonFormatter{
+			Config: cfg,
+		}
+	case FormatLogfmt:
+		return &logfmtFormatter{
+			Config: cfg,
+		}
+	default:
+		return &formatter{
+			Config:          cfg,
+			TimestampFormat: "2006-01-02 15:04:05",
+			Colours:         cfg.colour != ColourNever,
+			ForceColours:    cfg.colour == ColourAlways,
+		}
+	}
+}
+
 // formatter implements logrus.Formatter interface.
+// It holds no per entry state so it is safe to be
+// used from multiple goroutines.
 type formatter struct {
 	Config          *Config
 	Colours         bool
+	ForceColours    bool
 	TimestampFormat string
-	entry           *logrus.Entry
-	buf             *bytes.Buffer
+}
+
+// entryFormatter holds the state needed to format a
+// single entry, a new one is created for every call
+// to Format.
+type entryFormatter struct {
+	*formatter
+	entry *logrus.Entry
+	buf   *bytes.Buffer
+}
+
+// bufferPool is the pool of buffers used for formatting
+// entries to reduce allocations.
+var bufferPool = sync.Pool{
+	New: func() any {
+		return &bytes.Buffer{}
+	},
 }
 
 // Format building log message.
 func (f *formatter) Format(entry *logrus.Entry) ([]byte, error) {
-	if !f.Colours {
-		color.Disable()
+	b := bufferPool.Get().(*bytes.Buffer)
+	b.Reset()
+	defer bufferPool.Put(b)
+
+	ef := &entryFormatter{
+		formatter: f,
+		entry:     entry,
+		buf:       b,
 	}
-	b := &bytes.Buffer{}
-	f.buf = b
-	f.entry = entry
 
 	b.WriteString("[" + strings.ToUpper(f.Config.prefix) + "] ")
 
-	f.Time()
-	f.StatusCode()
-	f.Level()
-	f.IP()
-	f.Method()
-	f.URL()
-	f.Message()
-	f.Error()
-	f.Fields()
+	ef.Time()
+	ef.StatusCode()
+	ef.Level()
+	ef.IP()
+	ef.Method()
+	ef.URL()
+	ef.Message()
+	ef.Error()
+	ef.Fields()
 
 	str := b.String()
 	str = strings.TrimSuffix(str, "|")
@@ -64,9 +108,23 @@ func (f *formatter) Format(entry *logrus.Entry) ([]byte, error) {
 	return []byte(str), nil
 }
 
+// paint formats the string with the given style, if
+// colours are disabled on the formatter the plain
+// string is returned. Unless colours are forced, the
+// style is only applied if the terminal supports it.
+func (f *formatter) paint(style color.Style, format string, args ...any) string {
+	switch {
+	case !f.Colours:
+		return fmt.Sprintf(format, args...)
+	case f.ForceColours && style.Code() != "":
+		return color.StartSet + style.Code() + "m" + fmt.Sprintf(format, args...) + color.ResetSet
+	}
+	return style.Sprintf(format, args...)
+}
+
 // Time prints the timestamp for the log, if no format is
 // set on the formatter, time.StampMilli will be used.
-func (f *formatter) Time() {
+func (f *entryFormatter) Time() {
 	timestampFormat := f.TimestampFormat
 	if timestampFormat == "" {
 		timestampFormat = time.StampMilli
@@ -77,7 +135,7 @@ func (f *formatter) Time() {
 // StatusCode Prints the status code of the request, if
 // there is none set the log is config and the DefaultStatus will
 // be printed.
-func (f *formatter) StatusCode() {
+func (f *entryFormatter) StatusCode() {
 	f.buf.WriteString(" | ")
 
 	cc := color.Style{color.FgLightWhite, color.BgRed, color.OpBold}
@@ -85,7 +143,7 @@ func (f *formatter) StatusCode() {
 	status, ok := f.entry.Data["status_code"]
 	if !ok {
 		cc = color.Style{color.FgLightWhite, color.BgBlack, color.OpBold}
-		f.buf.WriteString(cc.Sprint(strings.ToUpper(f.Config.defaultStatus)))
+		f.buf.WriteString(f.paint(cc, "%s", strings.ToUpper(f.Config.defaultStatus)))
 	}
 
 	if codeInt, ok := status.(int); ok {
@@ -95,7 +153,7 @@ func (f *formatter) StatusCode() {
 	}
 
 	if status != "" && status != nil {
-		f.buf.WriteString(cc.Sprintf("%d", status))
+		f.buf.WriteString(f.paint(cc, "%d", status))
 	}
 
 	f.buf.WriteString(" | ")
@@ -103,7 +161,7 @@ func (f *formatter) StatusCode() {
 
 // Level Prints the entry level of the log entry in
 // uppercase.
-func (f *formatter) Level() {
+func (f *entryFormatter) Level() {
 	cc := color.Style{} //nolint
 	switch f.entry.Level {
 	case logrus.TraceLevel:
@@ -120,15 +178,15 @@ func (f *formatter) Level() {
 
 	level := strings.ToUpper(f.entry.Level.String())
 	if len(level) == 4 { //nolint
-		f.buf.WriteString(cc.Sprintf("[%s] ", level))
+		f.buf.WriteString(f.paint(cc, "[%s] ", level))
 		return
 	}
 
-	f.buf.WriteString(cc.Sprintf("[%s]", level))
+	f.buf.WriteString(f.paint(cc, "[%s]", level))
 }
 
 // IP prints the IP address if there is any.
-func (f *formatter) IP() {
+func (f *entryFormatter) IP() {
 	ip, ok := f.entry.Data["client_ip"].(string)
 	if ok {
 		f.buf.WriteString(fmt.Sprintf(" | %s | ", ip))
@@ -139,17 +197,17 @@ func (f *formatter) IP() {
 
 // Method prints the entry request method if there is one
 // set.
-func (f *formatter) Method() {
+func (f *entryFormatter) Method() {
 	method, ok := f.entry.Data["request_method"].(string)
 	if !ok {
 		return
 	}
 	rc := color.Style{color.FgLightWhite, color.BgBlue, color.OpBold}
-	f.buf.WriteString(rc.Sprintf("  %s   ", method))
+	f.buf.WriteString(f.paint(rc, "  %s   ", method))
 }
 
 // URL Prints the entry request url if there is one set.
-func (f *formatter) URL() {
+func (f *entryFormatter) URL() {
 	url, ok := f.entry.Data["request_url"].(string)
 	if ok {
 		f.buf.WriteString(fmt.Sprintf(" \"%s\" ", url))
@@ -157,7 +215,7 @@ func (f *formatter) URL() {
 }
 
 // Message prints the entry message if there is one set.
-func (f *formatter) Message() {
+func (f *entryFormatter) Message() {
 	//_, method := f.entry.Data["request_method"].(string)
 	err, _ := f.HasError()
 
@@ -175,7 +233,7 @@ func (f *formatter) Message() {
 }
 
 // Fields prints the entry fields.
-func (f *formatter) Fields() {
+func (f *entryFormatter) Fields() {
 	fields, ok := f.entry.Data["fields"].(logrus.Fields)
 	if !ok {
 		return
@@ -188,7 +246,7 @@ func (f *formatter) Fields() {
 
 // HasError determines if a error.HasError type has been
 // logged.
-func (f *formatter) HasError() (*errors.Error, bool) {
+func (f *entryFormatter) HasError() (*errors.Error, bool) {
 	e := f.entry.Data["error"]
 	if e == nil {
 		return nil, false
@@ -202,31 +260,33 @@ func (f *formatter) HasError() (*errors.Error, bool) {
 
 // Error prints out the error if there is one set. If the
 // error is nil, nothing will be printed.
-func (f *formatter) Error() {
+func (f *entryFormatter) Error() {
 	err, ok := f.HasError()
 	if !ok {
 		return
 	}
 
+	red := color.Style{color.FgRed}
+
 	f.buf.WriteString("|")
 
 	if err.Code != "" {
-		f.buf.WriteString(color.Red.Sprintf(" [code] "))
+		f.buf.WriteString(f.paint(red, " [code] "))
 		f.buf.WriteString(err.Code)
 	}
 
 	if err.Message != "" {
-		f.buf.WriteString(color.Red.Sprintf(" [msg] "))
+		f.buf.WriteString(f.paint(red, " [msg] "))
 		f.buf.WriteString(err.Message)
 	}
 
 	if err.Operation != "" {
-		f.buf.WriteString(color.Red.Sprintf(" [op] "))
+		f.buf.WriteString(f.paint(red, " [op] "))
 		f.buf.WriteString(err.Operation)
 	}
 
 	if err.Err != nil {
-		f.buf.WriteString(color.Red.Sprintf(" [error] "))
+		f.buf.WriteString(f.paint(red, " [error] "))
 		f.buf.WriteString(err.Err.Error())
 	}
 }
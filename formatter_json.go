@@ -0,0 +1,153 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+// jsonFormatter implements logrus.Formatter interface
+// and writes entries as single line JSON objects.
+type jsonFormatter struct {
+	Config          *Config
+	TimestampFormat string
+}
+
+// jsonError is the JSON representation of an
+// errors.Error attached to an entry.
+type jsonError struct {
+	Code      string `json:"code,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Operation string `json:"op,omitempty"`
+	Err       string `json:"err,omitempty"`
+	FileLine  string `json:"fileline,omitempty"`
+}
+
+// jsonReservedKeys are the keys written by the formatter
+// that cannot be overwritten by entry data.
+var jsonReservedKeys = map[string]struct{}{
+	"prefix":  {},
+	"service": {},
+	"version": {},
+	"level":   {},
+	"time":    {},
+	"message": {},
+}
+
+// Format building log message.
+func (f *jsonFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	const op = "Logger.JSONFormatter.Format"
+
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = time.RFC3339Nano
+	}
+
+	data := make(map[string]any, len(entry.Data)+6) //nolint
+	data["prefix"] = strings.ToUpper(f.Config.prefix)
+	data["service"] = f.Config.service
+	if f.Config.version != "" {
+		data["version"] = f.Config.version
+	}
+	data["level"] = entry.Level.String()
+	data["time"] = entry.Time.Format(timestampFormat)
+
+	msg := entry.Message
+	if m, ok := entry.Data["message"].(string); ok && msg == "" {
+		msg = m
+	}
+	if msg != "" {
+		data["message"] = msg
+	}
+
+	for k, v := range entry.Data {
+		switch k {
+		case types.ErrorKey:
+			if e := jsonErr(v); e != nil {
+				data[types.ErrorKey] = e
+			}
+			continue
+		case types.FieldKey:
+			data[types.FieldKey] = jsonValue(v)
+			continue
+		case "message":
+			continue
+		}
+		if _, ok := jsonReservedKeys[k]; ok {
+			k = "data." + k
+		}
+		data[k] = jsonValue(v)
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(data)
+	if err != nil {
+		return nil, &errors.Error{Code: errors.INTERNAL, Message: "Error marshalling entry to JSON", Operation: op, Err: err}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// jsonErr decomposes the value into a jsonError, nil will
+// be returned if the value could not be converted.
+func jsonErr(v any) *jsonError {
+	e := errors.ToError(v)
+	if e == nil {
+		return nil
+	}
+	je := &jsonError{
+		Code:      e.Code,
+		Message:   e.Message,
+		Operation: e.Operation,
+		FileLine:  e.FileLine(),
+	}
+	if e.Err != nil {
+		je.Err = e.Err.Error()
+	}
+	return je
+}
+
+// jsonFields converts each value within the map with
+// jsonValue.
+func jsonFields(fields map[string]any) map[string]any {
+	m := make(map[string]any, len(fields))
+	for k, v := range fields {
+		m[k] = jsonValue(v)
+	}
+	return m
+}
+
+// jsonValue converts errors to strings so they aren't
+// marshalled as empty objects, nested fields are
+// converted recursively.
+func jsonValue(v any) any {
+	switch t := v.(type) {
+	case error:
+		return t.Error()
+	case logrus.Fields:
+		return jsonFields(t)
+	case map[string]any:
+		return jsonFields(t)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ainsleyclark/errors"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+func (t *LoggerTestSuite) TestJSONFormatter() {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := map[string]struct {
+		entry *logrus.Entry
+		want  map[string]any
+	}{
+		"Message": {
+			&logrus.Entry{
+				Level:   logrus.InfoLevel,
+				Message: "message",
+			},
+			map[string]any{
+				"prefix":  "TEST",
+				"service": "service",
+				"version": "v0.0.1",
+				"level":   "info",
+				"time":    "2022-10-01T12:00:00Z",
+				"message": "message",
+			},
+		},
+		"Fields": {
+			&logrus.Entry{
+				Level: logrus.InfoLevel,
+				Data: logrus.Fields{
+					"fields": logrus.Fields{"key": "value", "err": fmt.Errorf("error")},
+				},
+			},
+			map[string]any{
+				"prefix":  "TEST",
+				"service": "service",
+				"version": "v0.0.1",
+				"level":   "info",
+				"time":    "2022-10-01T12:00:00Z",
+				"fields":  map[string]any{"key": "value", "err": "error"},
+			},
+		},
+		"Error": {
+			&logrus.Entry{
+				Level: logrus.ErrorLevel,
+				Data: logrus.Fields{
+					"error": &errors.Error{Code: "internal", Message: "message", Operation: "op", Err: fmt.Errorf("error")},
+				},
+			},
+			map[string]any{
+				"prefix":  "TEST",
+				"service": "service",
+				"version": "v0.0.1",
+				"level":   "error",
+				"time":    "2022-10-01T12:00:00Z",
+				"error": map[string]any{
+					"code":    "internal",
+					"message": "message",
+					"op":      "op",
+					"err":     "error",
+				},
+			},
+		},
+		"Nil Error": {
+			&logrus.Entry{
+				Level: logrus.InfoLevel,
+				Data: logrus.Fields{
+					"error": (*errors.Error)(nil),
+				},
+			},
+			map[string]any{
+				"prefix":  "TEST",
+				"service": "service",
+				"version": "v0.0.1",
+				"level":   "info",
+				"time":    "2022-10-01T12:00:00Z",
+			},
+		},
+		"HTTP": {
+			&logrus.Entry{
+				Level: logrus.InfoLevel,
+				Data: logrus.Fields{
+					"status_code":    200,
+					"client_ip":      "127.0.0.1",
+					"request_method": "GET",
+					"request_url":    "/page",
+					"message":        "message",
+				},
+			},
+			map[string]any{
+				"prefix":         "TEST",
+				"service":        "service",
+				"version":        "v0.0.1",
+				"level":          "info",
+				"time":           "2022-10-01T12:00:00Z",
+				"message":        "message",
+				"status_code":    float64(200),
+				"client_ip":      "127.0.0.1",
+				"request_method": "GET",
+				"request_url":    "/page",
+			},
+		},
+		"Reserved Key": {
+			&logrus.Entry{
+				Level: logrus.InfoLevel,
+				Data: logrus.Fields{
+					"level": "custom",
+				},
+			},
+			map[string]any{
+				"prefix":     "TEST",
+				"service":    "service",
+				"version":    "v0.0.1",
+				"level":      "info",
+				"time":       "2022-10-01T12:00:00Z",
+				"data.level": "custom",
+			},
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			test.entry.Time = now
+			f := jsonFormatter{
+				Config: &Config{
+					prefix:  "test",
+					service: "service",
+					version: "v0.0.1",
+				},
+			}
+			got, err := f.Format(test.entry)
+			t.NoError(err)
+			t.Equal(byte('\n'), got[len(got)-1])
+			m := map[string]any{}
+			t.NoError(json.Unmarshal(got, &m))
+			t.Equal(test.want, m)
+		})
+	}
+}
+
+func (t *LoggerTestSuite) TestJSONFormatter_Error() {
+	f := jsonFormatter{Config: &Config{}}
+	_, err := f.Format(&logrus.Entry{
+		Data: logrus.Fields{"key": make(chan int)},
+	})
+	t.ErrorContains(err, "Error marshalling entry to JSON")
+}
+
+func (t *LoggerTestSuite) TestNewFormatter() {
+	t.IsType(&formatter{}, newFormatter(&Config{}))
+	t.IsType(&jsonFormatter{}, newFormatter(&Config{format: FormatJSON}))
+	t.IsType(&logfmtFormatter{}, newFormatter(&Config{format: FormatLogfmt}))
+}
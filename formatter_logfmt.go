@@ -0,0 +1,184 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// logfmtFormatter implements logrus.Formatter interface
+// and writes entries as key=value pairs.
+type logfmtFormatter struct {
+	Config          *Config
+	TimestampFormat string
+}
+
+// logfmtReservedKeys are the keys written by the formatter
+// that cannot be overwritten by entry data.
+var logfmtReservedKeys = map[string]struct{}{
+	"time":    {},
+	"level":   {},
+	"prefix":  {},
+	"service": {},
+	"version": {},
+	"msg":     {},
+}
+
+// Format building log message.
+func (f *logfmtFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = time.RFC3339
+	}
+
+	b := &bytes.Buffer{}
+	logfmtPair(b, "time", entry.Time.Format(timestampFormat))
+	logfmtPair(b, "level", entry.Level.String())
+	logfmtPair(b, "prefix", strings.ToUpper(f.Config.prefix))
+	logfmtPair(b, "service", f.Config.service)
+	if f.Config.version != "" {
+		logfmtPair(b, "version", f.Config.version)
+	}
+
+	msg := entry.Message
+	if m, ok := entry.Data["message"].(string); ok && msg == "" {
+		msg = m
+	}
+	if msg != "" {
+		logfmtPair(b, "msg", msg)
+	}
+
+	if e := errors.ToError(entry.Data[types.ErrorKey]); e != nil {
+		if e.Code != "" {
+			logfmtPair(b, "error.code", e.Code)
+		}
+		if e.Message != "" {
+			logfmtPair(b, "error.message", e.Message)
+		}
+		if e.Operation != "" {
+			logfmtPair(b, "error.op", e.Operation)
+		}
+		if e.Err != nil {
+			logfmtPair(b, "error.err", e.Err.Error())
+		}
+		if e.FileLine() != "" {
+			logfmtPair(b, "error.fileline", e.FileLine())
+		}
+	}
+
+	data := make(map[string]any, len(entry.Data))
+	for k, v := range entry.Data {
+		if k == types.ErrorKey || k == types.FieldKey || k == "message" {
+			continue
+		}
+		data[k] = v
+	}
+	logfmtMap(b, data)
+
+	switch fields := entry.Data[types.FieldKey].(type) {
+	case logrus.Fields:
+		logfmtMap(b, fields)
+	case map[string]any:
+		logfmtMap(b, fields)
+	}
+
+	b.Truncate(b.Len() - 1)
+	b.WriteByte('\n')
+
+	return b.Bytes(), nil
+}
+
+// logfmtMap writes the map in key order, keys that clash
+// with the formatter's own keys are prefixed with
+// "data.".
+func logfmtMap(b *bytes.Buffer, m map[string]any) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key := k
+		if _, ok := logfmtReservedKeys[k]; ok {
+			key = "data." + k
+		}
+		logfmtPair(b, key, logfmtValue(m[k]))
+	}
+}
+
+// logfmtPair writes a single key=value pair followed by a
+// space, the value is quoted if required.
+func logfmtPair(b *bytes.Buffer, key, value string) {
+	b.WriteString(logfmtKey(key))
+	b.WriteByte('=')
+	if logfmtNeedsQuote(value) {
+		b.WriteString(strconv.Quote(value))
+	} else {
+		b.WriteString(value)
+	}
+	b.WriteByte(' ')
+}
+
+// logfmtKey replaces any characters that are not valid
+// within a logfmt key with an underscore.
+func logfmtKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == unicode.ReplacementChar {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtNeedsQuote determines if the value must be quoted
+// to be parsed correctly.
+func logfmtNeedsQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// logfmtValue converts the value to its string
+// representation.
+func logfmtValue(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case error:
+		return t.Error()
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return t.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
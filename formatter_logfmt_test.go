@@ -0,0 +1,104 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"github.com/ainsleyclark/errors"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+func (t *LoggerTestSuite) TestLogfmtFormatter() {
+	var (
+		now    = time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+		prefix = "time=2022-10-01T12:00:00Z level=%s prefix=TEST service=service version=v0.0.1"
+	)
+
+	tt := map[string]struct {
+		entry *logrus.Entry
+		want  string
+	}{
+		"Message": {
+			&logrus.Entry{
+				Level:   logrus.InfoLevel,
+				Message: "message",
+			},
+			fmt.Sprintf(prefix+" msg=message\n", "info"),
+		},
+		"Quoted": {
+			&logrus.Entry{
+				Level:   logrus.InfoLevel,
+				Message: "hello \"world\"",
+				Data:    logrus.Fields{"empty": "", "equals": "a=b"},
+			},
+			fmt.Sprintf(prefix+" msg=\"hello \\\"world\\\"\" empty=\"\" equals=\"a=b\"\n", "info"),
+		},
+		"Fields": {
+			&logrus.Entry{
+				Level: logrus.InfoLevel,
+				Data: logrus.Fields{
+					"fields": logrus.Fields{"b": 2, "a": "value", "my key": "x"},
+				},
+			},
+			fmt.Sprintf(prefix+" a=value b=2 my_key=x\n", "info"),
+		},
+		"Error": {
+			&logrus.Entry{
+				Level: logrus.ErrorLevel,
+				Data: logrus.Fields{
+					"error": &errors.Error{Code: "internal", Message: "bad thing", Operation: "op", Err: fmt.Errorf("error")},
+				},
+			},
+			fmt.Sprintf(prefix+" error.code=internal error.message=\"bad thing\" error.op=op error.err=error\n", "error"),
+		},
+		"HTTP": {
+			&logrus.Entry{
+				Level: logrus.InfoLevel,
+				Data: logrus.Fields{
+					"status_code":    200,
+					"client_ip":      "127.0.0.1",
+					"request_method": "GET",
+					"request_url":    "/page",
+					"message":        "message",
+					"latency_time":   time.Millisecond,
+				},
+			},
+			fmt.Sprintf(prefix+" msg=message client_ip=127.0.0.1 latency_time=1ms request_method=GET request_url=/page status_code=200\n", "info"),
+		},
+		"Reserved Key": {
+			&logrus.Entry{
+				Level: logrus.InfoLevel,
+				Data:  logrus.Fields{"level": "custom"},
+			},
+			fmt.Sprintf(prefix+" data.level=custom\n", "info"),
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			test.entry.Time = now
+			f := logfmtFormatter{
+				Config: &Config{
+					prefix:  "test",
+					service: "service",
+					version: "v0.0.1",
+				},
+			}
+			got, err := f.Format(test.entry)
+			t.NoError(err)
+			t.Equal(test.want, string(got))
+		})
+	}
+}
@@ -14,9 +14,15 @@
 package logger
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/hooks/stdout"
+	"github.com/gookit/color"
 	"github.com/sirupsen/logrus"
+	"io"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -181,3 +187,106 @@ func (t *LoggerTestSuite) TestFormatter() {
 		})
 	}
 }
+
+// syncWriter is an io.Writer that can be written to from
+// multiple goroutines.
+type syncWriter struct {
+	mtx sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.buf.Write(p)
+}
+
+func (t *LoggerTestSuite) TestFormatter_Concurrent() {
+	const (
+		goroutines = 50
+		entries    = 100
+	)
+
+	var (
+		out    = &syncWriter{}
+		errOut = &syncWriter{}
+		l      = logrus.New()
+		wg     = sync.WaitGroup{}
+	)
+
+	l.SetLevel(logrus.TraceLevel)
+	l.SetOutput(io.Discard)
+	l.SetFormatter(&formatter{
+		Config:  &Config{prefix: "test", defaultStatus: "test"},
+		Colours: false,
+	})
+	l.AddHook(&stdout.Hook{Writer: errOut, LogLevels: []logrus.Level{logrus.ErrorLevel}})
+	l.AddHook(&stdout.Hook{Writer: out, LogLevels: []logrus.Level{logrus.InfoLevel}})
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < entries; j++ {
+				msg := fmt.Sprintf("goroutine-%d-%d", i, j)
+				if j%2 == 0 {
+					l.WithField("message", msg).Info(msg)
+					continue
+				}
+				l.WithField("error", &errors.Error{Code: "code", Message: msg}).Error(msg)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(out.buf.String()+errOut.buf.String()), "\n")
+	t.Len(lines, goroutines*entries)
+	for _, line := range lines {
+		t.True(strings.HasPrefix(line, "[TEST] "), line)
+		t.Regexp(`goroutine-\d+-\d+$`, line)
+	}
+}
+
+func (t *LoggerTestSuite) TestFormatter_Colours() {
+	entry := &logrus.Entry{Level: logrus.InfoLevel, Message: "message"}
+
+	plain := formatter{Config: &Config{prefix: "test", defaultStatus: "test"}, Colours: false}
+	coloured := formatter{Config: &Config{prefix: "test", defaultStatus: "test"}, Colours: true}
+
+	got, err := plain.Format(entry)
+	t.NoError(err)
+	t.NotContains(string(got), "\x1b[")
+
+	// Formatting without colours should not affect another formatter.
+	got, err = coloured.Format(entry)
+	t.NoError(err)
+	if color.SupportColor() {
+		t.Contains(string(got), "\x1b[")
+	}
+
+	// Forced colours are written regardless of the terminal.
+	forced := formatter{Config: &Config{prefix: "test", defaultStatus: "test"}, Colours: true, ForceColours: true}
+	got, err = forced.Format(entry)
+	t.NoError(err)
+	t.Contains(string(got), "\x1b[")
+}
+
+func (t *LoggerTestSuite) TestNewFormatter_Colour() {
+	tt := map[string]struct {
+		input  ColourMode
+		colour bool
+		force  bool
+	}{
+		"Auto":   {ColourAuto, true, false},
+		"Always": {ColourAlways, true, true},
+		"Never":  {ColourNever, false, false},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			f := newFormatter(&Config{colour: test.input}).(*formatter)
+			t.Equal(test.colour, f.Colours)
+			t.Equal(test.force, f.ForceColours)
+		})
+	}
+}
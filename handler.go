@@ -33,8 +33,14 @@ type FireHook struct {
 	Latency      float64
 }
 
-// Fire fires a FireHook to Logrus from a http request.
+// Fire fires a FireHook to the default Logger from a
+// http request.
 func Fire(f FireHook) {
+	std.Fire(f)
+}
+
+// Fire fires a FireHook to Logrus from a http request.
+func (l *Logger) Fire(f FireHook) {
 	endTime := time.Now()
 	latency := time.Since(f.RequestTime)
 
@@ -60,9 +66,9 @@ func Fire(f FireHook) {
 	}
 
 	if f.Status >= 200 && f.Status < 300 {
-		L.WithFields(fields).Info(f.Message)
+		l.Logger.WithFields(fields).Info(f.Message)
 		return
 	}
 
-	L.WithFields(fields).Error(f.Message)
+	l.Logger.WithFields(fields).Error(f.Message)
 }
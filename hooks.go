@@ -15,21 +15,27 @@ package logger
 
 import (
 	"context"
-	"github.com/ainsleyclark/logger/internal/hooks/slack"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/batch"
+	"github.com/ainsleyclark/logger/internal/dlq"
 	"github.com/ainsleyclark/logger/internal/hooks/workplace"
+	"github.com/ainsleyclark/logger/internal/queue"
 	"github.com/ainsleyclark/logger/types"
 	"github.com/ainsleyclark/mogrus"
 	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var (
 	// newMogrus is an alias for mogrus.New
 	newMogrus = mogrus.New
-	// newWP is an alias for notify.NewFireHook
-	newWP = workplace.NewHook
-	mtx   = sync.Mutex{}
+	// newWP is an alias for workplace.New
+	newWP = workplace.New
+	// mongoConnect is an alias for mongo.Connect
+	mongoConnect = mongo.Connect
 )
 
 type (
@@ -37,139 +43,363 @@ type (
 	fireFunc func(*logrus.Entry) error
 )
 
+const (
+	// fatalFlushTimeout is the maximum amount of time spent
+	// draining pending deliveries before a Fatal or Panic
+	// entry exits.
+	fatalFlushTimeout = time.Second * 5
+)
+
 // addHooks adds all hooks to the logger.
-func addHooks(ctx context.Context, cfg *Config) error {
+func addHooks(ctx context.Context, l *Logger) error {
 	d := &defaultHook{
-		config: cfg,
+		config: l.config,
+		logger: l.Logger,
+		levels: l.levels,
 	}
 
-	err := d.addWorkplaceHook()
+	err := d.add(ctx)
 	if err != nil {
+		// Stop anything started before the error, such as the
+		// Mongo connection and the queue workers.
+		_ = d.close(context.Background())
 		return err
 	}
 
-	err = d.addMogrusHook(ctx)
+	l.AddHook(d)
+	l.hook = d
+
+	return nil
+}
+
+// add creates the notifiers, the Mogrus hook and the dead
+// letter queue.
+func (hook *defaultHook) add(ctx context.Context) error {
+	err := hook.addNotifiers()
 	if err != nil {
 		return err
 	}
 
-	d.addSlackHook()
-
-	L.AddHook(d)
+	err = hook.addMogrusHook(ctx)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return hook.addDeadLetter()
 }
 
 // defaultHook is the default hook for processing logger entries.
 type defaultHook struct {
-	config *Config
-	wp     fireFunc
-	mogrus fireFunc
-	slack  fireFunc
+	config  *Config
+	logger  *logrus.Logger
+	levels  *sinkLevels
+	pending tracker
+	closed  int32
+	// Deliveries are made with ctx, which is cancelled once
+	// the hook is closed so that retries and sends that
+	// are still in flight are stopped.
+	ctx       context.Context
+	cancel    context.CancelFunc
+	ctxOnce   sync.Once
+	notifiers []notifierConfig
+	mogrus    fireFunc
+	mongo     *queue.Queue[*logrus.Entry]
+	// The Mongo client, only set if the logger connected
+	// to Mongo itself.
+	mongoClient *mongo.Client
+	// Batched Mongo writes, only set if batching is enabled.
+	mongoBatch func(context.Context, []*logrus.Entry) error
+	batch      *batch.Batcher[*logrus.Entry]
+	// Failed deliveries, only set if the dead letter queue
+	// is enabled.
+	dlq          *dlq.Queue
+	replayNow    chan struct{}
+	replayCancel context.CancelFunc
+	replayDone   chan struct{}
 }
 
+// internalKey is the context key used to mark entries
+// logged by the hook itself, so they are not delivered
+// again which would cause a feedback loop.
+type internalKey struct{}
+
 // Fire will be called when some logging function is
 // called with current hook. It will format log
 // entry to string and write it to
 // appropriate writer
 func (hook *defaultHook) Fire(entry *logrus.Entry) error {
-	if entry == nil {
+	if entry == nil || atomic.LoadInt32(&hook.closed) == 1 {
 		return nil
 	}
-	if hook.wp != nil {
-		if hook.config.workplace.Report(types.Entry(*entry)) {
-			err := hook.wp(entry)
-			if err != nil {
-				L.WithError(err).Error() // Don't return, still have processing to do.
-			}
+	if entry.Context != nil && entry.Context.Value(internalKey{}) != nil {
+		return nil
+	}
+	hook.notify(types.Entry(*entry))
+	if hook.mongo != nil {
+		if hook.levels.Enabled(SinkMongo, entry.Level) && hook.config.mongo.Report(types.Entry(*entry)) {
+			// Logrus modifies the entry once the hooks have
+			// fired, so a copy is queued.
+			e := *entry
+			hook.mongo.Push(&e)
 		}
 	}
-	if hook.slack != nil {
-		if hook.config.slack.Report(types.Entry(*entry)) {
-			err := hook.slack(entry)
-			if err != nil {
-				L.WithError(err).Error() // Don't return, still have processing to do.
-			}
+	// Fatal and Panic entries exit the process as soon as the
+	// hooks have fired, send the digests and summaries that
+	// are waiting and drain everything that is pending so
+	// the entry isn't lost.
+	if entry.Level <= logrus.FatalLevel {
+		hook.flushLimiters()
+		hook.flushDigests()
+		ctx, cancel := context.WithTimeout(context.Background(), fatalFlushTimeout)
+		defer cancel()
+		_ = hook.flush(ctx)
+	}
+	return nil
+}
+
+// flush waits for all pending deliveries to complete or
+// until the context is cancelled.
+func (hook *defaultHook) flush(ctx context.Context) error {
+	const op = "Logger.Flush"
+	err := hook.pending.Wait(ctx)
+	if err != nil {
+		return errors.NewInternal(err, "Error waiting for pending entries to be delivered", op)
+	}
+	if hook.mongo != nil {
+		err = hook.mongo.Wait(ctx)
+		if err != nil {
+			return errors.NewInternal(err, "Error waiting for pending entries to be written to Mongo", op)
+		}
+	}
+	if hook.batch != nil {
+		err = hook.batch.Flush(ctx)
+		if err != nil {
+			return errors.NewInternal(err, "Error waiting for batched entries to be written to Mongo", op)
+		}
+	}
+	return nil
+}
+
+// close stops any new entries from being delivered to
+// the remote hooks and flushes the pending ones. Any
+// deliveries still being retried once the context is
+// done are cancelled.
+func (hook *defaultHook) close(ctx context.Context) error {
+	const op = "Logger.Close"
+	atomic.StoreInt32(&hook.closed, 1)
+	hook.flushLimiters()
+	hook.closeDigests()
+	err := hook.flush(ctx)
+	hook.deliveryContext()
+	hook.cancel()
+	if err != nil {
+		return err
+	}
+	if hook.mongo != nil {
+		err = hook.mongo.Close(ctx)
+		if err != nil {
+			return errors.NewInternal(err, "Error closing the Mongo queue", op)
 		}
 	}
-	if hook.mogrus != nil {
-		if hook.config.mongo.Report(types.Entry(*entry)) {
-			go func(fire fireFunc) {
-				mtx.Lock()
-				err := fire(entry)
-				if err != nil {
-					L.WithError(err).Error()
-				}
-				mtx.Unlock()
-			}(hook.mogrus)
+	if hook.batch != nil {
+		err = hook.batch.Close(ctx)
+		if err != nil {
+			return errors.NewInternal(err, "Error closing the Mongo batch", op)
+		}
+	}
+	err = hook.stopReplay(ctx)
+	if err != nil {
+		return errors.NewInternal(err, "Error waiting for the dead letter queue to stop replaying", op)
+	}
+	if hook.mongoClient != nil {
+		err = hook.mongoClient.Disconnect(ctx)
+		if err != nil {
+			return errors.NewInternal(err, "Error disconnecting from Mongo", op)
 		}
 	}
 	return nil
 }
 
+// deliveryContext returns the context that deliveries are
+// made with, it's cancelled when the hook is closed.
+func (hook *defaultHook) deliveryContext() context.Context {
+	hook.ctxOnce.Do(func() {
+		hook.ctx, hook.cancel = context.WithCancel(context.Background())
+	})
+	return hook.ctx
+}
+
+// stats returns the delivery counters for the hook.
+func (hook *defaultHook) stats() Stats {
+	s := Stats{}
+	if hook.mongo != nil {
+		s.MongoQueued = hook.mongo.Len()
+		if hook.batch != nil {
+			s.MongoQueued += hook.batch.Len()
+		}
+		s.MongoDropped = hook.mongo.Dropped()
+	}
+	if hook.dlq != nil {
+		s.DeadLetter = hook.dlq.Len()
+	}
+	return s
+}
+
+// logError passes an error that occurred delivering an
+// entry to the error callback, or logs it if there is
+// none. The entry is marked as internal so it's only
+// written to stdout.
+func (hook *defaultHook) logError(err error) {
+	if hook.config != nil && hook.config.onError != nil {
+		hook.config.onError(err)
+		return
+	}
+	ctx := context.WithValue(context.Background(), internalKey{}, true)
+	hook.logger.WithContext(ctx).WithError(err).Error()
+}
+
 // Levels Define on which log levels this hook would
 // trigger.
 func (hook *defaultHook) Levels() []logrus.Level {
 	return logrus.AllLevels
 }
 
-// addWorkplaceHook adds the Workplace hook if
-// the thread and token exists.
-func (hook *defaultHook) addWorkplaceHook() error {
-	if hook.config.workplace.Thread != "" && hook.config.workplace.Token != "" {
-		wpHook, err := newWP(workplace.Options{
-			Token:         hook.config.workplace.Token,
-			Thread:        hook.config.workplace.Thread,
-			FormatMessage: hook.config.workplace.Formatter,
-			Args: types.FormatMessageArgs{
-				Service: hook.config.service,
-				Version: hook.config.version,
-				Prefix:  hook.config.prefix,
-			},
-		})
+// addMogrusHook adds the Mogrus hook if
+// the client exists, or connects to Mongo
+// if a URI has been set.
+func (hook *defaultHook) addMogrusHook(ctx context.Context) error {
+	if hook.config.mongo.Collection == nil && hook.config.mongo.URI != "" {
+		client, col, err := connectMongo(ctx, hook.config.mongo)
 		if err != nil {
 			return err
 		}
-		hook.wp = wpHook.Fire
+		hook.mongoClient = client
+		hook.config.mongo.Collection = col
 	}
-	return nil
-}
-
-// addMogrusHook adds the Mogrus hook if
-// the client exists.
-func (hook *defaultHook) addMogrusHook(ctx context.Context) error {
 	if hook.config.mongo.Collection != nil {
-		mogrusHook, err := newMogrus(ctx, mogrus.Options{
-			Collection: hook.config.mongo.Collection,
-			ExpirationLevels: mogrus.ExpirationLevels{
-				logrus.TraceLevel: time.Hour * 24,
-				logrus.DebugLevel: time.Hour * 24,
-				logrus.InfoLevel:  time.Hour * 24 * 7,
-				logrus.ErrorLevel: time.Hour * 24 * 7 * 4,
-				logrus.WarnLevel:  time.Hour * 24 * 7 * 4,
-				logrus.PanicLevel: time.Hour * 24 * 7 * 4 * 6,
-				logrus.FatalLevel: time.Hour * 24 * 7 * 4 * 6,
-			},
-		})
+		levels := hook.config.mongo.expirationLevels()
+		opts := mogrus.Options{
+			Collection:       hook.config.mongo.Collection,
+			ExpirationLevels: levels,
+		}
+		mogrusHook, err := newMogrus(ctx, opts)
+		if isIndexConflict(err) {
+			// The expiration has changed since the indexes
+			// were created, update them and try again.
+			err = updateExpiration(ctx, opts.Collection, levels)
+			if err != nil {
+				return err
+			}
+			mogrusHook, err = newMogrus(ctx, opts)
+		}
 		if err != nil {
 			return err
 		}
 		hook.mogrus = mogrusHook.Fire
+		if hook.config.mongo.BatchSize > 0 {
+			collection := hook.config.mongo.Collection
+			hook.mongoBatch = func(ctx context.Context, entries []*logrus.Entry) error {
+				return writeMongoBatch(ctx, collection, levels, entries)
+			}
+			hook.batch = hook.newMongoBatcher()
+		}
+		hook.mongo = hook.newMongoQueue()
 	}
 	return nil
 }
 
-func (hook *defaultHook) addSlackHook() {
-	if hook.config.slack.Token != "" && hook.config.slack.Channel != "" {
-		hook.slack = slack.NewHook(slack.Options{
-			Token:         hook.config.slack.Token,
-			Channel:       hook.config.slack.Channel,
-			FormatMessage: hook.config.slack.Formatter,
-			Args: types.FormatMessageArgs{
-				Service: hook.config.service,
-				Version: hook.config.version,
-				Prefix:  hook.config.prefix,
-			},
-		}).Fire
+// newMongoBatcher creates the Batcher used for writing
+// entries to Mongo in bulk.
+func (hook *defaultHook) newMongoBatcher() *batch.Batcher[*logrus.Entry] {
+	return batch.New(hook.config.mongo.BatchSize, hook.config.mongo.BatchInterval, func(ctx context.Context, entries []*logrus.Entry) {
+		err := hook.mongoBatch(ctx, entries)
+		if err != nil {
+			hook.logError(err)
+			failed := make([]types.Entry, len(entries))
+			for i, entry := range entries {
+				failed[i] = types.Entry(*entry)
+			}
+			hook.deadLetter(SinkMongo, failed...)
+			return
+		}
+		hook.recovered()
+	})
+}
+
+// newMongoQueue creates the bounded queue used to write
+// entries to Mongo in the background.
+func (hook *defaultHook) newMongoQueue() *queue.Queue[*logrus.Entry] {
+	return queue.New(queue.Options{
+		Size:    hook.config.mongo.QueueSize,
+		Workers: hook.config.mongo.Workers,
+		Policy:  hook.config.mongo.Overflow,
+	}, func(entry *logrus.Entry) {
+		if hook.batch != nil {
+			hook.batch.Add(entry)
+			return
+		}
+		err := hook.mogrus(entry)
+		if err != nil {
+			hook.logError(err)
+			hook.deadLetter(SinkMongo, types.Entry(*entry))
+			return
+		}
+		hook.recovered()
+	})
+}
+
+// tracker counts deliveries that are being processed in
+// the background so that they can be drained before
+// the process exits.
+type tracker struct {
+	mtx     sync.Mutex
+	count   int
+	drained chan struct{}
+}
+
+// Go runs the function in a new goroutine and tracks it
+// until it returns.
+func (t *tracker) Go(fn func()) {
+	t.mtx.Lock()
+	t.count++
+	t.mtx.Unlock()
+	go func() {
+		defer t.done()
+		fn()
+	}()
+}
+
+// done marks a delivery as complete and releases any
+// waiters once there are none left.
+func (t *tracker) done() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.count--
+	if t.count == 0 && t.drained != nil {
+		close(t.drained)
+		t.drained = nil
+	}
+}
+
+// Wait blocks until there are no pending deliveries or
+// the context is done, in which case the context's
+// error is returned.
+func (t *tracker) Wait(ctx context.Context) error {
+	t.mtx.Lock()
+	if t.count == 0 {
+		t.mtx.Unlock()
+		return nil
+	}
+	if t.drained == nil {
+		t.drained = make(chan struct{})
+	}
+	drained := t.drained
+	t.mtx.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
@@ -14,94 +14,81 @@
 package logger
 
 import (
+	"bytes"
+	"context"
 	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/hooks/stdout"
 	"github.com/ainsleyclark/logger/types"
+	"github.com/ainsleyclark/mogrus"
 	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 func (t *LoggerTestSuite) TestDefaultHook_Fire() {
-	L.SetOutput(io.Discard)
+	l := logrus.New()
+	l.SetOutput(io.Discard)
 
 	tt := map[string]struct {
 		input *logrus.Entry
-		hook  defaultHook
+		hook  *defaultHook
 		want  any
 	}{
 		"Nil": {
 			nil,
-			defaultHook{},
+			&defaultHook{},
 			nil,
 		},
 		"OK": {
 			&logrus.Entry{},
-			defaultHook{
-				wp:     func(entry *logrus.Entry) error { return nil },
-				mogrus: func(entry *logrus.Entry) error { return nil },
-				slack:  func(entry *logrus.Entry) error { return nil },
-				config: &Config{
-					workplace: workplaceConfig{Report: types.DefaultReportFn},
-					mongo:     mongoConfig{Report: types.DefaultReportFn},
-					slack:     slackConfig{Report: types.DefaultReportFn},
-				},
-			},
-			nil,
-		},
-		"Workplace Dont Report": {
-			&logrus.Entry{},
-			defaultHook{
-				wp: func(entry *logrus.Entry) error {
-					return nil
-				},
-				config: &Config{
-					workplace: workplaceConfig{Report: func(e types.Entry) bool {
-						return false
-					}},
-				},
-			},
-			nil,
-		},
-		"With Workplace Error": {
-			&logrus.Entry{},
-			defaultHook{
-				wp: func(entry *logrus.Entry) error {
-					return errors.New("wp error")
+			&defaultHook{
+				notifiers: []notifierConfig{
+					{Name: "notifier", Notifier: NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+						return nil
+					}), Report: types.DefaultReportFn},
 				},
+				mogrus: func(entry *logrus.Entry) error { return nil },
 				config: &Config{
-					workplace: workplaceConfig{Report: types.DefaultReportFn},
+					mongo: mongoConfig{Report: types.DefaultReportFn},
 				},
 			},
 			nil,
 		},
-		"Slack Dont Report": {
+		"Notifier Dont Report": {
 			&logrus.Entry{},
-			defaultHook{
-				slack: func(entry *logrus.Entry) error {
-					return nil
-				},
-				config: &Config{
-					slack: slackConfig{Report: func(e types.Entry) bool {
+			&defaultHook{
+				notifiers: []notifierConfig{
+					{Name: "notifier", Notifier: NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+						return nil
+					}), Report: func(e types.Entry) bool {
 						return false
 					}},
 				},
+				config: &Config{},
 			},
 			nil,
 		},
-		"With Slack Error": {
+		"With Notifier Error": {
 			&logrus.Entry{},
-			defaultHook{
-				slack: func(entry *logrus.Entry) error {
-					return errors.New("wp error")
-				},
-				config: &Config{
-					slack: slackConfig{Report: types.DefaultReportFn},
+			&defaultHook{
+				notifiers: []notifierConfig{
+					{Name: "notifier", Notifier: NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+						return errors.New("notifier error")
+					}), Report: types.DefaultReportFn},
 				},
+				config: &Config{},
 			},
 			nil,
 		},
 		"Mogrus Dont Report": {
 			&logrus.Entry{},
-			defaultHook{
+			&defaultHook{
 				mogrus: func(entry *logrus.Entry) error {
 					return nil
 				},
@@ -115,7 +102,7 @@ func (t *LoggerTestSuite) TestDefaultHook_Fire() {
 		},
 		"With Mogrus Error": {
 			&logrus.Entry{},
-			defaultHook{
+			&defaultHook{
 				mogrus: func(entry *logrus.Entry) error {
 					return errors.New("mogrus error")
 				},
@@ -129,6 +116,10 @@ func (t *LoggerTestSuite) TestDefaultHook_Fire() {
 
 	for name, test := range tt {
 		t.Run(name, func() {
+			test.hook.logger = l
+			if test.hook.mogrus != nil {
+				test.hook.mongo = test.hook.newMongoQueue()
+			}
 			err := test.hook.Fire(test.input)
 			if err != nil {
 				t.Contains(err.Error(), test.want)
@@ -138,3 +129,384 @@ func (t *LoggerTestSuite) TestDefaultHook_Fire() {
 		})
 	}
 }
+
+func (t *LoggerTestSuite) TestDefaultHook_Flush() {
+	release := make(chan struct{})
+	var fired int32
+	hook := &defaultHook{
+		logger: logrus.New(),
+		mogrus: func(entry *logrus.Entry) error {
+			<-release
+			atomic.AddInt32(&fired, 1)
+			return nil
+		},
+		config: &Config{
+			mongo: mongoConfig{Report: types.DefaultReportFn},
+		},
+	}
+	hook.mongo = hook.newMongoQueue()
+
+	err := hook.Fire(&logrus.Entry{Level: logrus.InfoLevel})
+	t.NoError(err)
+
+	t.Run("Deadline", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		err := hook.flush(ctx)
+		t.ErrorContains(err, "Error waiting for pending entries")
+	})
+
+	t.Run("Drained", func() {
+		close(release)
+		err := hook.flush(context.Background())
+		t.NoError(err)
+		t.Equal(int32(1), atomic.LoadInt32(&fired))
+	})
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_Close() {
+	var fired int32
+	hook := &defaultHook{
+		logger: logrus.New(),
+		notifiers: []notifierConfig{
+			{Name: "notifier", Notifier: NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+				time.Sleep(time.Millisecond * 10)
+				atomic.AddInt32(&fired, 1)
+				return nil
+			}), Report: types.DefaultReportFn},
+		},
+		config: &Config{},
+	}
+
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel}))
+	t.NoError(hook.close(context.Background()))
+	t.Equal(int32(1), atomic.LoadInt32(&fired))
+
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel}))
+	t.NoError(hook.flush(context.Background()))
+	t.Equal(int32(1), atomic.LoadInt32(&fired))
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_FireFatal() {
+	var fired int32
+	hook := &defaultHook{
+		logger: logrus.New(),
+		notifiers: []notifierConfig{
+			{Name: "notifier", Notifier: NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+				time.Sleep(time.Millisecond * 10)
+				atomic.AddInt32(&fired, 1)
+				return nil
+			}), Report: types.DefaultReportFn},
+		},
+		config: &Config{},
+	}
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.FatalLevel}))
+	t.Equal(int32(1), atomic.LoadInt32(&fired))
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_Mongo() {
+	var (
+		started = make(chan struct{}, 5)
+		release = make(chan struct{})
+	)
+	hook := &defaultHook{
+		logger: logrus.New(),
+		mogrus: func(entry *logrus.Entry) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		},
+		config: &Config{
+			mongo: mongoConfig{
+				Report:    types.DefaultReportFn,
+				QueueSize: 1,
+				Workers:   1,
+				Overflow:  OverflowDropNewest,
+			},
+		},
+	}
+	hook.mongo = hook.newMongoQueue()
+
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.InfoLevel}))
+	<-started
+	for i := 0; i < 4; i++ {
+		t.NoError(hook.Fire(&logrus.Entry{Level: logrus.InfoLevel}))
+	}
+
+	// One entry is being written, one is queued and the rest
+	// are dropped.
+	t.Equal(Stats{MongoQueued: 1, MongoDropped: 3}, hook.stats())
+
+	close(release)
+	t.NoError(hook.close(context.Background()))
+	t.Equal(Stats{MongoQueued: 0, MongoDropped: 3}, hook.stats())
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_Internal() {
+	buf := &bytes.Buffer{}
+	l := logrus.New()
+	l.SetOutput(buf)
+
+	var fired int32
+	hook := &defaultHook{
+		logger: l,
+		mogrus: func(entry *logrus.Entry) error {
+			atomic.AddInt32(&fired, 1)
+			return errors.New("mogrus error")
+		},
+		config: &Config{
+			mongo: mongoConfig{Report: types.DefaultReportFn},
+		},
+	}
+	hook.mongo = hook.newMongoQueue()
+	l.AddHook(hook)
+
+	l.Error("error")
+	t.NoError(hook.flush(context.Background()))
+
+	// The error logged from writing to Mongo should not be
+	// sent back to Mongo.
+	t.Equal(int32(1), atomic.LoadInt32(&fired))
+	t.Contains(buf.String(), "mogrus error")
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_MongoBatch() {
+	var (
+		mtx     sync.Mutex
+		batches [][]*logrus.Entry
+	)
+	hook := &defaultHook{
+		logger: logrus.New(),
+		mongoBatch: func(ctx context.Context, entries []*logrus.Entry) error {
+			mtx.Lock()
+			defer mtx.Unlock()
+			batches = append(batches, entries)
+			return nil
+		},
+		config: &Config{
+			mongo: mongoConfig{
+				Report:        types.DefaultReportFn,
+				QueueSize:     10,
+				Workers:       1,
+				BatchSize:     2,
+				BatchInterval: time.Hour,
+			},
+		},
+	}
+	hook.batch = hook.newMongoBatcher()
+	hook.mongo = hook.newMongoQueue()
+
+	for i := 0; i < 3; i++ {
+		t.NoError(hook.Fire(&logrus.Entry{Level: logrus.InfoLevel}))
+	}
+	t.NoError(hook.mongo.Wait(context.Background()))
+
+	mtx.Lock()
+	t.Len(batches, 1)
+	mtx.Unlock()
+	t.Equal(1, hook.stats().MongoQueued)
+
+	// Remaining entries are written on flush.
+	t.NoError(hook.flush(context.Background()))
+	mtx.Lock()
+	t.Len(batches, 2)
+	t.Len(batches[1], 1)
+	mtx.Unlock()
+
+	t.NoError(hook.Fire(&logrus.Entry{Level: logrus.InfoLevel}))
+	t.NoError(hook.close(context.Background()))
+	mtx.Lock()
+	t.Len(batches, 3)
+	mtx.Unlock()
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_AddMogrusHook() {
+	orig := newMogrus
+	defer func() {
+		newMogrus = orig
+	}()
+
+	var got mogrus.Options
+	newMogrus = func(ctx context.Context, opts mogrus.Options) (logrus.Hook, error) {
+		got = opts
+		return &stdout.Hook{}, nil
+	}
+
+	cfg := &Config{mongo: mongoConfig{
+		Collection: &mongo.Collection{},
+		Expiration: ExpirationLevels{logrus.InfoLevel: time.Hour, logrus.ErrorLevel: time.Hour},
+		NoExpiry:   []logrus.Level{logrus.ErrorLevel},
+	}}
+	hook := &defaultHook{config: cfg.assignDefaults(), logger: logrus.New()}
+
+	t.NoError(hook.addMogrusHook(context.Background()))
+	t.Equal(ExpirationLevels{logrus.InfoLevel: time.Hour}, got.ExpirationLevels)
+	t.NotNil(hook.mongo)
+	t.NoError(hook.close(context.Background()))
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_AddMogrusHookConflict() {
+	origMogrus, origModify := newMogrus, modifyTTLIndex
+	defer func() {
+		newMogrus, modifyTTLIndex = origMogrus, origModify
+	}()
+
+	conflict := errors.NewInternal(mongo.CommandError{Code: mongoIndexOptionsConflict}, "Error creating indexes", "Mogrus.New")
+
+	tt := map[string]struct {
+		modify func(key string) error
+		calls  int
+		want   string
+	}{
+		"Updated": {
+			func(key string) error {
+				return nil
+			},
+			2,
+			"",
+		},
+		"Not Found": {
+			func(key string) error {
+				if key == "expiry.ttl-error" {
+					return mongo.CommandError{Code: mongoIndexNotFound}
+				}
+				return nil
+			},
+			2,
+			"",
+		},
+		"Error": {
+			func(key string) error {
+				return errors.New("collmod error")
+			},
+			1,
+			"Error updating the duration of the TTL index expiry.ttl-error, drop the index or run collMod with expireAfterSeconds set to 60",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			calls := 0
+			newMogrus = func(ctx context.Context, opts mogrus.Options) (logrus.Hook, error) {
+				calls++
+				if calls == 1 {
+					return nil, conflict
+				}
+				return &stdout.Hook{}, nil
+			}
+
+			var keys []string
+			modifyTTLIndex = func(ctx context.Context, col *mongo.Collection, key string, seconds int32) error {
+				keys = append(keys, key)
+				return test.modify(key)
+			}
+
+			cfg := &Config{mongo: mongoConfig{
+				Collection: &mongo.Collection{},
+				Expiration: ExpirationLevels{logrus.ErrorLevel: time.Minute, logrus.InfoLevel: time.Hour},
+			}}
+			hook := &defaultHook{config: cfg.assignDefaults(), logger: logrus.New()}
+
+			err := hook.addMogrusHook(context.Background())
+			t.Equal(test.calls, calls)
+			if test.want != "" {
+				t.ErrorContains(err, test.want)
+				return
+			}
+			t.NoError(err)
+			t.Equal([]string{"expiry.ttl-error", "expiry.ttl-info"}, keys)
+			t.NoError(hook.close(context.Background()))
+		})
+	}
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_AddMogrusHookURI() {
+	origMogrus, origConnect := newMogrus, mongoConnect
+	defer func() {
+		newMogrus, mongoConnect = origMogrus, origConnect
+	}()
+
+	var got mogrus.Options
+	newMogrus = func(ctx context.Context, opts mogrus.Options) (logrus.Hook, error) {
+		got = opts
+		return &stdout.Hook{}, nil
+	}
+
+	cfg := &Config{mongo: mongoConfig{URI: "mongodb://localhost:27017", Database: "database", Name: "collection"}}
+	hook := &defaultHook{config: cfg.assignDefaults(), logger: logrus.New()}
+	t.NoError(hook.addMogrusHook(context.Background()))
+	t.NotNil(hook.mongoClient)
+	t.Equal("collection", got.Collection.Name())
+	t.Equal("database", got.Collection.Database().Name())
+	t.NoError(hook.close(context.Background()))
+
+	t.Run("Error", func() {
+		mongoConnect = func(ctx context.Context, opts ...*options.ClientOptions) (*mongo.Client, error) {
+			return nil, errors.New("connect error")
+		}
+		hook := &defaultHook{config: cfg, logger: logrus.New()}
+		hook.config.mongo.Collection = nil
+		err := hook.addMogrusHook(context.Background())
+		t.ErrorContains(err, "connect error")
+	})
+}
+
+func (t *LoggerTestSuite) TestAddHooks_Teardown() {
+	origMogrus, origConnect := newMogrus, mongoConnect
+	defer func() {
+		newMogrus, mongoConnect = origMogrus, origConnect
+	}()
+
+	var client *mongo.Client
+	mongoConnect = func(ctx context.Context, opts ...*options.ClientOptions) (*mongo.Client, error) {
+		c, err := origConnect(ctx, opts...)
+		client = c
+		return c, err
+	}
+	newMogrus = func(ctx context.Context, opts mogrus.Options) (logrus.Hook, error) {
+		return &stdout.Hook{}, nil
+	}
+
+	tt := map[string]struct {
+		options *Options
+		mogrus  func(ctx context.Context, opts mogrus.Options) (logrus.Hook, error)
+		want    string
+	}{
+		"Mogrus": {
+			NewOptions(),
+			func(ctx context.Context, opts mogrus.Options) (logrus.Hook, error) {
+				return nil, errors.New("mogrus error")
+			},
+			"mogrus error",
+		},
+		"Dead Letter": {
+			NewOptions().DeadLetter(filepath.Join(t.writeTempFile(), "dlq"), 0),
+			newMogrus,
+			"dead letter queue",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			client = nil
+			newMogrus = test.mogrus
+			opts := NewOptions().
+				Service("service").
+				WithMongo(NewMongoURIOptions("mongodb://localhost:27017", "database", "collection"))
+			_, err := NewLogger(context.Background(), opts, test.options)
+			t.ErrorContains(err, test.want)
+
+			// The connection opened by the Logger should be closed.
+			t.NotNil(client)
+			t.ErrorIs(client.Ping(context.Background(), nil), mongo.ErrClientDisconnected)
+		})
+	}
+}
+
+// writeTempFile creates an empty file and returns its
+// path.
+func (t *LoggerTestSuite) writeTempFile() string {
+	path := filepath.Join(t.T().TempDir(), "file")
+	t.NoError(os.WriteFile(path, nil, os.ModePerm))
+	return path
+}
@@ -0,0 +1,176 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Batcher buffers items and flushes them together when
+// the batch reaches its size or when the interval
+// elapses, whichever comes first.
+type Batcher[T any] struct {
+	size    int
+	flushFn func(context.Context, []T)
+	mtx     sync.Mutex
+	items   []T
+	// The amount of batches being flushed, idle is closed
+	// once it reaches zero.
+	inflight int
+	idle     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// New creates a new Batcher and starts the interval timer.
+// The flush function is called with every batch and is
+// never called with an empty batch.
+func New[T any](size int, interval time.Duration, fn func(ctx context.Context, items []T)) *Batcher[T] {
+	if size < 1 {
+		size = 1
+	}
+	b := &Batcher[T]{
+		size:    size,
+		flushFn: fn,
+		items:   make([]T, 0, size),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go b.tick(interval)
+	return b
+}
+
+// Add appends an item to the batch, the batch is flushed
+// if it has reached its size.
+func (b *Batcher[T]) Add(item T) {
+	b.mtx.Lock()
+	b.items = append(b.items, item)
+	if len(b.items) < b.size {
+		b.mtx.Unlock()
+		return
+	}
+	items := b.take()
+	b.mtx.Unlock()
+	defer b.finished()
+	b.flushFn(context.Background(), items)
+}
+
+// Len returns the amount of items waiting to be flushed.
+func (b *Batcher[T]) Len() int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return len(b.items)
+}
+
+// Flush sends the items within the batch to the flush
+// function, if there are any, and waits for batches
+// that are already being flushed to complete. If the
+// context is done first, the context's error is
+// returned.
+func (b *Batcher[T]) Flush(ctx context.Context) error {
+	b.flush(ctx)
+	return b.wait(ctx)
+}
+
+// Close stops the interval timer and flushes the
+// remaining items.
+func (b *Batcher[T]) Close(ctx context.Context) error {
+	b.once.Do(func() {
+		close(b.stop)
+		<-b.done
+	})
+	return b.Flush(ctx)
+}
+
+// flush sends the items within the batch to the flush
+// function without waiting for other flushes.
+func (b *Batcher[T]) flush(ctx context.Context) {
+	b.mtx.Lock()
+	if len(b.items) == 0 {
+		b.mtx.Unlock()
+		return
+	}
+	items := b.take()
+	b.mtx.Unlock()
+	defer b.finished()
+	b.flushFn(ctx, items)
+}
+
+// take returns the current batch and replaces it with a
+// new one, the batch is marked as being flushed until
+// finished is called. The mutex must be held by the
+// caller.
+func (b *Batcher[T]) take() []T {
+	items := b.items
+	b.items = make([]T, 0, b.size)
+	b.inflight++
+	return items
+}
+
+// finished marks a batch as flushed and releases any
+// waiters once there are none left.
+func (b *Batcher[T]) finished() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.inflight--
+	if b.inflight == 0 && b.idle != nil {
+		close(b.idle)
+		b.idle = nil
+	}
+}
+
+// wait blocks until no batches are being flushed or the
+// context is done.
+func (b *Batcher[T]) wait(ctx context.Context) error {
+	b.mtx.Lock()
+	if b.inflight == 0 {
+		b.mtx.Unlock()
+		return nil
+	}
+	if b.idle == nil {
+		b.idle = make(chan struct{})
+	}
+	idle := b.idle
+	b.mtx.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tick flushes the batch every interval until the
+// Batcher is closed.
+func (b *Batcher[T]) tick(interval time.Duration) {
+	defer close(b.done)
+	if interval <= 0 {
+		<-b.stop
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.flush(context.Background())
+		case <-b.stop:
+			return
+		}
+	}
+}
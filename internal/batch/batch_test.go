@@ -0,0 +1,128 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder records the batches that have been flushed.
+type recorder struct {
+	mtx     sync.Mutex
+	batches [][]int
+}
+
+func (r *recorder) flush(ctx context.Context, items []int) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.batches = append(r.batches, items)
+}
+
+func (r *recorder) get() [][]int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.batches
+}
+
+func TestNew(t *testing.T) {
+	b := New(0, 0, func(ctx context.Context, items []int) {})
+	defer b.Close(context.Background())
+	assert.Equal(t, 1, b.size)
+}
+
+func TestBatcher_Add(t *testing.T) {
+	r := &recorder{}
+	b := New(3, 0, r.flush)
+	defer b.Close(context.Background())
+
+	for i := 0; i < 7; i++ {
+		b.Add(i)
+	}
+
+	assert.Equal(t, [][]int{{0, 1, 2}, {3, 4, 5}}, r.get())
+	assert.Equal(t, 1, b.Len())
+}
+
+func TestBatcher_Interval(t *testing.T) {
+	r := &recorder{}
+	b := New(100, time.Millisecond*5, r.flush)
+	defer b.Close(context.Background())
+
+	b.Add(1)
+	b.Add(2)
+
+	assert.Eventually(t, func() bool {
+		return len(r.get()) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, [][]int{{1, 2}}, r.get())
+}
+
+func TestBatcher_Flush(t *testing.T) {
+	r := &recorder{}
+	b := New(100, 0, r.flush)
+	defer b.Close(context.Background())
+
+	assert.NoError(t, b.Flush(context.Background()))
+	assert.Nil(t, r.get())
+
+	b.Add(1)
+	assert.NoError(t, b.Flush(context.Background()))
+	assert.Equal(t, [][]int{{1}}, r.get())
+}
+
+func TestBatcher_Close(t *testing.T) {
+	r := &recorder{}
+	b := New(100, time.Hour, r.flush)
+
+	b.Add(1)
+	assert.NoError(t, b.Close(context.Background()))
+	assert.NoError(t, b.Close(context.Background()))
+	assert.Equal(t, [][]int{{1}}, r.get())
+}
+
+func TestBatcher_FlushInFlight(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+		flushed = make(chan []int, 1)
+	)
+	b := New(100, time.Millisecond, func(ctx context.Context, items []int) {
+		close(started)
+		<-release
+		flushed <- items
+	})
+	defer b.Close(context.Background())
+
+	// The interval starts flushing the batch, Flush must wait
+	// for it rather than return with nothing to send.
+	b.Add(1)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	assert.ErrorIs(t, b.Flush(ctx), context.DeadlineExceeded)
+
+	go close(release)
+	assert.NoError(t, b.Flush(context.Background()))
+	select {
+	case items := <-flushed:
+		assert.Equal(t, []int{1}, items)
+	default:
+		t.Fatal("flush returned before the batch was written")
+	}
+}
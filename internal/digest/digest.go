@@ -0,0 +1,241 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package digest
+
+import (
+	"fmt"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Aggregator collects entries over a window and passes
+	// them to the callback as a single Digest, grouped
+	// by the error's code and operation.
+	Aggregator struct {
+		fn     func(Digest)
+		mtx    sync.Mutex
+		start  time.Time
+		groups map[string]*Group
+		order  []string
+		stop   chan struct{}
+		done   chan struct{}
+		once   sync.Once
+	}
+	// Digest is the summary of the entries collected within
+	// a window.
+	Digest struct {
+		Start  time.Time
+		End    time.Time
+		Groups []Group
+	}
+	// Group is a set of entries with the same error code and
+	// operation. Entries without an error are grouped by
+	// their level and message.
+	Group struct {
+		Code      string
+		Operation string
+		Count     int
+		FirstSeen time.Time
+		LastSeen  time.Time
+		// Sample is the first entry within the group.
+		Sample types.Entry
+	}
+)
+
+// New creates an Aggregator that sends a Digest to the
+// callback every window, if any entries have been
+// added.
+func New(window time.Duration, fn func(Digest)) *Aggregator {
+	a := &Aggregator{
+		fn:     fn,
+		start:  time.Now(),
+		groups: make(map[string]*Group),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go a.tick(window)
+	return a
+}
+
+// Add adds the entry to the group it belongs to.
+func (a *Aggregator) Add(entry types.Entry) {
+	seen := entry.Time
+	if seen.IsZero() {
+		seen = time.Now()
+	}
+
+	key, code, op := groupKey(entry)
+
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	g, ok := a.groups[key]
+	if !ok {
+		g = &Group{
+			Code:      code,
+			Operation: op,
+			FirstSeen: seen,
+			Sample:    entry,
+		}
+		a.groups[key] = g
+		a.order = append(a.order, key)
+	}
+	g.Count++
+	if seen.Before(g.FirstSeen) {
+		g.FirstSeen = seen
+	}
+	if seen.After(g.LastSeen) {
+		g.LastSeen = seen
+	}
+}
+
+// Len returns the amount of entries waiting to be sent.
+func (a *Aggregator) Len() int {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	n := 0
+	for _, g := range a.groups {
+		n += g.Count
+	}
+	return n
+}
+
+// Flush sends the Digest for the entries collected so far
+// and starts a new window, nothing is sent if no
+// entries have been added.
+func (a *Aggregator) Flush() {
+	a.mtx.Lock()
+	d := Digest{Start: a.start, End: time.Now()}
+	for _, key := range a.order {
+		d.Groups = append(d.Groups, *a.groups[key])
+	}
+	a.start = d.End
+	a.groups = make(map[string]*Group)
+	a.order = nil
+	a.mtx.Unlock()
+	if len(d.Groups) > 0 {
+		a.fn(d)
+	}
+}
+
+// Close stops the window timer and sends the remaining
+// entries.
+func (a *Aggregator) Close() {
+	a.once.Do(func() {
+		close(a.stop)
+		<-a.done
+	})
+	a.Flush()
+}
+
+// tick flushes the Aggregator every window until it's
+// closed.
+func (a *Aggregator) tick(window time.Duration) {
+	defer close(a.done)
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.Flush()
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+// groupKey returns the key used to group the entry along
+// with the error's code and operation.
+func groupKey(entry types.Entry) (key, code, op string) {
+	if err := entry.Error(); err != nil {
+		return "error\x00" + err.Code + "\x00" + err.Operation, err.Code, err.Operation
+	}
+	return "entry\x00" + entry.Level.String() + "\x00" + entry.Message, "", ""
+}
+
+// Count returns the total amount of entries within the
+// Digest.
+func (d Digest) Count() int {
+	n := 0
+	for _, g := range d.Groups {
+		n += g.Count
+	}
+	return n
+}
+
+// Level returns the most severe level of the entries
+// within the Digest.
+func (d Digest) Level() logrus.Level {
+	level := logrus.TraceLevel
+	for _, g := range d.Groups {
+		if g.Sample.Level < level {
+			level = g.Sample.Level
+		}
+	}
+	return level
+}
+
+// Message returns the Digest as a readable message, with
+// the count, first and last seen times and a sample
+// for each group.
+func (d Digest) Message() string {
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("Digest of %d %s from %s to %s\n",
+		d.Count(), plural(d.Count(), "entry", "entries"), d.Start.Format(time.RFC3339), d.End.Format(time.RFC3339)))
+	for _, g := range d.Groups {
+		buf.WriteString("\n")
+		buf.WriteString(fmt.Sprintf("%s (%d %s)\n", g.Title(), g.Count, plural(g.Count, "entry", "entries")))
+		buf.WriteString(fmt.Sprintf("First seen: %s\n", g.FirstSeen.Format(time.RFC3339)))
+		buf.WriteString(fmt.Sprintf("Last seen: %s\n", g.LastSeen.Format(time.RFC3339)))
+		buf.WriteString(fmt.Sprintf("Sample: %s\n", g.SampleMessage()))
+	}
+	return buf.String()
+}
+
+// Title returns the name of the group, the error's code
+// and operation or the level of the entries.
+func (g Group) Title() string {
+	if g.Code == "" && g.Operation == "" {
+		return strings.ToUpper(g.Sample.Level.String())
+	}
+	return fmt.Sprintf("[%s] %s", g.Code, g.Operation)
+}
+
+// SampleMessage returns the message of the sample entry,
+// including the error if there is one.
+func (g Group) SampleMessage() string {
+	err := g.Sample.Error()
+	switch {
+	case err == nil:
+		return g.Sample.Message
+	case err.Err == nil:
+		return err.Message
+	case err.Message == "":
+		return err.Err.Error()
+	}
+	return err.Message + ": " + err.Err.Error()
+}
+
+// plural returns the singular form if n is one, otherwise
+// the plural form.
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
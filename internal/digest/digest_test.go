@@ -0,0 +1,171 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package digest
+
+import (
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder records the digests that have been sent.
+type recorder struct {
+	mtx     sync.Mutex
+	digests []Digest
+}
+
+func (r *recorder) send(d Digest) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.digests = append(r.digests, d)
+}
+
+func (r *recorder) get() []Digest {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.digests
+}
+
+var now = time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func entry(code, op string, seen time.Time) types.Entry {
+	return types.Entry{
+		Level:   logrus.WarnLevel,
+		Time:    seen,
+		Message: "message",
+		Data:    logrus.Fields{types.ErrorKey: &errors.Error{Code: code, Message: "Error finding user", Operation: op, Err: errors.New("conn refused")}},
+	}
+}
+
+func TestAggregator_Add(t *testing.T) {
+	r := &recorder{}
+	a := New(time.Hour, r.send)
+	defer a.Close()
+
+	a.Add(entry(errors.INTERNAL, "Repo.Find", now.Add(time.Minute)))
+	a.Add(entry(errors.INTERNAL, "Repo.Find", now))
+	a.Add(entry(errors.INTERNAL, "Repo.Find", now.Add(time.Minute*2)))
+	a.Add(entry(errors.NOTFOUND, "Repo.Find", now))
+	a.Add(types.Entry{Level: logrus.ErrorLevel, Message: "no error"})
+	assert.Equal(t, 5, a.Len())
+
+	a.Flush()
+	assert.Equal(t, 0, a.Len())
+
+	got := r.get()
+	assert.Len(t, got, 1)
+	d := got[0]
+	assert.Equal(t, 5, d.Count())
+	assert.Equal(t, logrus.ErrorLevel, d.Level())
+	assert.Len(t, d.Groups, 3)
+
+	g := d.Groups[0]
+	assert.Equal(t, errors.INTERNAL, g.Code)
+	assert.Equal(t, "Repo.Find", g.Operation)
+	assert.Equal(t, 3, g.Count)
+	assert.Equal(t, now, g.FirstSeen)
+	assert.Equal(t, now.Add(time.Minute*2), g.LastSeen)
+	assert.Equal(t, now.Add(time.Minute), g.Sample.Time)
+
+	assert.Equal(t, errors.NOTFOUND, d.Groups[1].Code)
+	assert.Equal(t, 1, d.Groups[1].Count)
+	assert.Equal(t, "no error", d.Groups[2].Sample.Message)
+	assert.False(t, d.Groups[2].LastSeen.IsZero())
+}
+
+func TestAggregator_Flush(t *testing.T) {
+	r := &recorder{}
+	a := New(time.Hour, r.send)
+	defer a.Close()
+
+	a.Flush()
+	assert.Nil(t, r.get())
+}
+
+func TestAggregator_Window(t *testing.T) {
+	r := &recorder{}
+	a := New(time.Millisecond*5, r.send)
+	defer a.Close()
+
+	a.Add(entry(errors.INTERNAL, "op", now))
+	assert.Eventually(t, func() bool {
+		return len(r.get()) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 1, r.get()[0].Count())
+}
+
+func TestAggregator_Close(t *testing.T) {
+	r := &recorder{}
+	a := New(time.Hour, r.send)
+
+	a.Add(entry(errors.INTERNAL, "op", now))
+	a.Close()
+	a.Close()
+	assert.Len(t, r.get(), 1)
+}
+
+func TestDigest_Message(t *testing.T) {
+	d := Digest{
+		Start: now,
+		End:   now.Add(time.Minute * 10),
+		Groups: []Group{
+			{Code: errors.INTERNAL, Operation: "Repo.Find", Count: 2, FirstSeen: now, LastSeen: now.Add(time.Minute), Sample: entry(errors.INTERNAL, "Repo.Find", now)},
+			{Count: 1, FirstSeen: now, LastSeen: now, Sample: types.Entry{Level: logrus.WarnLevel, Message: "slow request"}},
+		},
+	}
+	want := `Digest of 3 entries from 2022-10-01T12:00:00Z to 2022-10-01T12:10:00Z
+
+[internal] Repo.Find (2 entries)
+First seen: 2022-10-01T12:00:00Z
+Last seen: 2022-10-01T12:01:00Z
+Sample: Error finding user: conn refused
+
+WARNING (1 entry)
+First seen: 2022-10-01T12:00:00Z
+Last seen: 2022-10-01T12:00:00Z
+Sample: slow request
+`
+	assert.Equal(t, want, d.Message())
+}
+
+func TestGroup_SampleMessage(t *testing.T) {
+	tt := map[string]struct {
+		input *errors.Error
+		want  string
+	}{
+		"Message": {
+			&errors.Error{Message: "message"},
+			"message",
+		},
+		"Error": {
+			&errors.Error{Err: errors.New("error")},
+			"error",
+		},
+		"Both": {
+			&errors.Error{Message: "message", Err: errors.New("error")},
+			"message: error",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			g := Group{Sample: types.Entry{Data: logrus.Fields{types.ErrorKey: test.input}}}
+			assert.Equal(t, test.want, g.SampleMessage())
+		})
+	}
+}
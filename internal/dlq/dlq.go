@@ -0,0 +1,275 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ext is the extension of the files records are stored in.
+const ext = ".json"
+
+// ErrFull is returned by Push when storing the record
+// would exceed the maximum size of the queue.
+var ErrFull = errors.New("dead letter queue is full")
+
+type (
+	// Queue is a disk-backed queue of entries that failed
+	// to be delivered. Each record is stored in its own
+	// file within the directory so that it survives
+	// restarts.
+	Queue struct {
+		dir     string
+		maxSize int64
+		mtx     sync.Mutex
+		size    int64
+		seq     uint64
+		// replaying guards against concurrent replays.
+		replaying sync.Mutex
+	}
+	// Record is an entry that failed to be delivered to
+	// a sink.
+	Record struct {
+		Sink    string         `json:"sink"`
+		Time    time.Time      `json:"time"`
+		Level   logrus.Level   `json:"level"`
+		Message string         `json:"message"`
+		Data    map[string]any `json:"data,omitempty"`
+		Error   *Error         `json:"error,omitempty"`
+	}
+	// Error is the error attached to the entry, the
+	// fileline is not kept.
+	Error struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		Operation string `json:"operation"`
+		Err       string `json:"err"`
+	}
+)
+
+// Open creates the directory if it doesn't exist and
+// returns a Queue containing any records that have
+// been left from a previous run.
+func Open(dir string, maxSize int64) (*Queue, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, err
+	}
+	q := &Queue{dir: dir, maxSize: maxSize}
+	files, err := q.files()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		q.size += info.Size()
+	}
+	return q, nil
+}
+
+// Push stores the record on disk. ErrFull is returned if
+// storing the record would exceed the maximum size.
+func (q *Queue) Push(r Record) error {
+	buf, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if q.maxSize > 0 && q.size+int64(len(buf)) > q.maxSize {
+		return ErrFull
+	}
+
+	// Write to a temporary file first, so partially
+	// written records are never replayed.
+	q.seq++
+	name := filepath.Join(q.dir, fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), q.seq%1000000, ext))
+	tmp := name + ".tmp"
+	err = os.WriteFile(tmp, buf, 0o600)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp, name)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	q.size += int64(len(buf))
+
+	return nil
+}
+
+// Replay calls fn for every record in the order they were
+// stored. Records are removed once fn returns nil and
+// kept otherwise. Replaying stops once the context is
+// cancelled. The amount of records replayed is
+// returned.
+func (q *Queue) Replay(ctx context.Context, fn func(r Record) error) (int, error) {
+	q.replaying.Lock()
+	defer q.replaying.Unlock()
+
+	files, err := q.files()
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, f := range files {
+		if ctx.Err() != nil {
+			return replayed, ctx.Err()
+		}
+		buf, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var r Record
+		if json.Unmarshal(buf, &r) != nil {
+			// The record is corrupt and can never be
+			// replayed, keep it for inspection.
+			_ = os.Rename(f, f+".corrupt")
+			q.sub(int64(len(buf)))
+			continue
+		}
+		if fn(r) != nil {
+			continue
+		}
+		if os.Remove(f) == nil {
+			q.sub(int64(len(buf)))
+			replayed++
+		}
+	}
+
+	return replayed, nil
+}
+
+// Len returns the amount of records within the queue.
+func (q *Queue) Len() int {
+	files, err := q.files()
+	if err != nil {
+		return 0
+	}
+	return len(files)
+}
+
+// Size returns the size of the records within the queue
+// in bytes.
+func (q *Queue) Size() int64 {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return q.size
+}
+
+// sub removes n bytes from the size of the queue.
+func (q *Queue) sub(n int64) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	q.size -= n
+	if q.size < 0 {
+		q.size = 0
+	}
+}
+
+// files returns the record files within the directory
+// sorted by the time they were written.
+func (q *Queue) files() ([]string, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ext) {
+			continue
+		}
+		files = append(files, filepath.Join(q.dir, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// NewRecord creates a Record from the entry for the sink
+// passed. Values that can't be encoded as JSON are
+// stored as strings.
+func NewRecord(sink string, entry types.Entry) Record {
+	r := Record{
+		Sink:    sink,
+		Time:    entry.Time,
+		Level:   entry.Level,
+		Message: entry.Message,
+	}
+	for k, v := range entry.Data {
+		if k == types.ErrorKey {
+			continue
+		}
+		if r.Data == nil {
+			r.Data = make(map[string]any, len(entry.Data))
+		}
+		if _, err := json.Marshal(v); err != nil {
+			v = fmt.Sprintf("%v", v)
+		}
+		r.Data[k] = v
+	}
+	if e := entry.Error(); e != nil {
+		r.Error = &Error{
+			Code:      e.Code,
+			Message:   e.Message,
+			Operation: e.Operation,
+		}
+		if e.Err != nil {
+			r.Error.Err = e.Err.Error()
+		}
+	}
+	return r
+}
+
+// Entry converts the record back to an entry.
+func (r Record) Entry() types.Entry {
+	e := types.Entry{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Data:    make(logrus.Fields, len(r.Data)+1),
+	}
+	for k, v := range r.Data {
+		e.Data[k] = v
+	}
+	if r.Error != nil {
+		err := &errors.Error{
+			Code:      r.Error.Code,
+			Message:   r.Error.Message,
+			Operation: r.Error.Operation,
+		}
+		if r.Error.Err != "" {
+			err.Err = errors.New(r.Error.Err)
+		}
+		e.Data[types.ErrorKey] = err
+	}
+	return e
+}
@@ -0,0 +1,158 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dlq
+
+import (
+	"context"
+	"errors"
+	pkgerrors "github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpen(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dlq")
+	q, err := Open(dir, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, q.Push(Record{Sink: "slack"}))
+
+	// Records are kept between runs.
+	q, err = Open(dir, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, q.Len())
+	assert.Greater(t, q.Size(), int64(0))
+}
+
+func TestOpen_Error(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(file, nil, 0o600))
+	_, err := Open(file, 0)
+	assert.Error(t, err)
+}
+
+func TestQueue_Push(t *testing.T) {
+	q, err := Open(t.TempDir(), 200)
+	assert.NoError(t, err)
+
+	assert.NoError(t, q.Push(Record{Sink: "slack"}))
+	assert.ErrorIs(t, q.Push(Record{Sink: "slack", Message: string(make([]byte, 200))}), ErrFull)
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestQueue_Replay(t *testing.T) {
+	q, err := Open(t.TempDir(), 0)
+	assert.NoError(t, err)
+
+	for _, sink := range []string{"a", "b", "a", "c"} {
+		assert.NoError(t, q.Push(Record{Sink: sink}))
+	}
+
+	var order []string
+	n, err := q.Replay(context.Background(), func(r Record) error {
+		order = append(order, r.Sink)
+		if r.Sink == "b" {
+			return errors.New("error")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []string{"a", "b", "a", "c"}, order)
+	assert.Equal(t, 1, q.Len())
+
+	n, err = q.Replay(context.Background(), func(r Record) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 0, q.Len())
+	assert.Equal(t, int64(0), q.Size())
+}
+
+func TestQueue_ReplayCancelled(t *testing.T) {
+	q, err := Open(t.TempDir(), 0)
+	assert.NoError(t, err)
+	assert.NoError(t, q.Push(Record{Sink: "a"}))
+	assert.NoError(t, q.Push(Record{Sink: "b"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n, err := q.Replay(ctx, func(r Record) error {
+		cancel()
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestQueue_ReplayCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	q, err := Open(dir, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "1.json"), []byte("{"), 0o600))
+
+	n, err := q.Replay(context.Background(), func(r Record) error {
+		t.Fatal("corrupt record should not be replayed")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 0, q.Len())
+	_, err = os.Stat(filepath.Join(dir, "1.json.corrupt"))
+	assert.NoError(t, err)
+}
+
+func TestRecord(t *testing.T) {
+	now := time.Now().UTC().Round(time.Second)
+	entry := types.Entry{
+		Time:    now,
+		Level:   logrus.ErrorLevel,
+		Message: "message",
+		Data: map[string]any{
+			types.ErrorKey: pkgerrors.NewInternal(errors.New("error"), "message", "op"),
+			types.FieldKey: map[string]any{"key": "value"},
+			"func":         func() {},
+		},
+	}
+
+	q, err := Open(t.TempDir(), 0)
+	assert.NoError(t, err)
+	assert.NoError(t, q.Push(NewRecord("slack", entry)))
+
+	var got types.Entry
+	_, err = q.Replay(context.Background(), func(r Record) error {
+		assert.Equal(t, "slack", r.Sink)
+		got = r.Entry()
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, now, got.Time)
+	assert.Equal(t, logrus.ErrorLevel, got.Level)
+	assert.Equal(t, "message", got.Message)
+	assert.Equal(t, types.Fields{"key": "value"}, got.Fields())
+	assert.IsType(t, "", got.Data["func"])
+
+	e := got.Error()
+	assert.Equal(t, pkgerrors.INTERNAL, e.Code)
+	assert.Equal(t, "message", e.Message)
+	assert.Equal(t, "op", e.Operation)
+	assert.Equal(t, "error", e.Err.Error())
+	assert.Equal(t, entry.Fingerprint(), got.Fingerprint())
+}
@@ -0,0 +1,188 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ainsleyclark/logger/internal/hooks"
+	"github.com/ainsleyclark/logger/internal/httputil"
+	"github.com/ainsleyclark/logger/types"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Embed limits, see
+// https://discord.com/developers/docs/resources/channel#embed-object-embed-limits
+const (
+	maxTitle       = 256
+	maxDescription = 4096
+	maxFields      = 25
+	maxFieldName   = 256
+	maxFieldValue  = 1024
+	maxFooter      = 2048
+	maxTotal       = 6000
+)
+
+type (
+	// Notifier represents the Discord notifier for log
+	// entries.
+	Notifier struct {
+		client  *http.Client
+		options Options
+	}
+	// Options defines the configuration needed to send logs
+	// to a Discord webhook.
+	Options struct {
+		URL     string
+		Timeout time.Duration
+	}
+	// message is the body sent to the webhook.
+	message struct {
+		Embeds []embed `json:"embeds"`
+	}
+	// embed is a Discord rich embed.
+	embed struct {
+		Title       string  `json:"title,omitempty"`
+		Description string  `json:"description,omitempty"`
+		Color       int     `json:"color"`
+		Timestamp   string  `json:"timestamp,omitempty"`
+		Fields      []field `json:"fields,omitempty"`
+		Footer      *footer `json:"footer,omitempty"`
+	}
+	// field is a single name and value pair of an embed.
+	field struct {
+		Name   string `json:"name"`
+		Value  string `json:"value"`
+		Inline bool   `json:"inline,omitempty"`
+	}
+	// footer is the footer of an embed.
+	footer struct {
+		Text string `json:"text"`
+	}
+)
+
+// New creates a new Discord notifier.
+func New(opts Options) *Notifier {
+	if opts.Timeout == 0 {
+		opts.Timeout = hooks.DefaultTimeout
+	}
+	return &Notifier{
+		client:  &http.Client{Timeout: opts.Timeout},
+		options: opts,
+	}
+}
+
+// Send renders the entry as an embed and posts it to the
+// webhook.
+func (n *Notifier) Send(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+	body, err := json.Marshal(message{
+		Embeds: []embed{newEmbed(entry, args)},
+	})
+	if err != nil {
+		return err
+	}
+
+	return httputil.PostJSON(ctx, n.client, n.options.URL, body, nil, "discord")
+}
+
+// newEmbed creates the embed for the entry, truncating
+// values so that it stays within Discord's limits. The
+// description is the entry message, or the formatted
+// message if a formatter has been set.
+func newEmbed(entry types.Entry, args types.FormatMessageArgs) embed {
+	description := entry.Message
+	if args.Formatter != nil {
+		description = args.Message(entry)
+	}
+
+	e := embed{
+		Title:       hooks.Truncate(strings.ToUpper(entry.Level.String())+" | "+args.Service, maxTitle),
+		Description: hooks.Truncate(description, maxDescription),
+		Color:       hooks.Colour(entry.Level),
+	}
+	if !entry.Time.IsZero() {
+		e.Timestamp = entry.Time.Format(time.RFC3339)
+	}
+
+	if err := entry.Error(); err != nil {
+		e.addField("Code", err.Code, true)
+		e.addField("Operation", err.Operation, true)
+		e.addField("Fileline", err.FileLine(), false)
+		e.addField("Message", err.Message, false)
+		if err.Err != nil {
+			e.addField("Error", err.Err.Error(), false)
+		}
+	}
+
+	fields := entry.Fields()
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		e.addField(k, fmt.Sprintf("%v", fields[k]), true)
+	}
+
+	text := args.Service
+	if args.Version != "" {
+		text += " v" + strings.TrimPrefix(args.Version, "v")
+	}
+	if text != "" {
+		e.Footer = &footer{Text: hooks.Truncate(text, maxFooter)}
+	}
+
+	// Drop fields and then trim the description if the
+	// embed as a whole is still too large.
+	for len(e.Fields) > 0 && e.length()-utf8.RuneCountInString(e.Description) > maxTotal {
+		e.Fields = e.Fields[:len(e.Fields)-1]
+	}
+	if over := e.length() - maxTotal; over > 0 {
+		e.Description = hooks.Truncate(e.Description, utf8.RuneCountInString(e.Description)-over)
+	}
+
+	return e
+}
+
+// addField appends a field to the embed if the value isn't
+// empty and the maximum amount of fields hasn't been
+// reached.
+func (e *embed) addField(name, value string, inline bool) {
+	if value == "" || len(e.Fields) >= maxFields {
+		return
+	}
+	e.Fields = append(e.Fields, field{
+		Name:   hooks.Truncate(name, maxFieldName),
+		Value:  hooks.Truncate(value, maxFieldValue),
+		Inline: inline,
+	})
+}
+
+// length returns the amount of characters counted towards
+// the total embed limit.
+func (e *embed) length() int {
+	n := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+	for _, f := range e.Fields {
+		n += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+	}
+	if e.Footer != nil {
+		n += utf8.RuneCountInString(e.Footer.Text)
+	}
+	return n
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/hooks"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNew(t *testing.T) {
+	got := New(Options{URL: "http://localhost"})
+	assert.Equal(t, hooks.DefaultTimeout, got.client.Timeout)
+	assert.Equal(t, "http://localhost", got.options.URL)
+}
+
+func TestNotifier_Send(t *testing.T) {
+	tt := map[string]struct {
+		status int
+		want   any
+	}{
+		"Success": {
+			http.StatusNoContent,
+			nil,
+		},
+		"Bad Status": {
+			http.StatusTooManyRequests,
+			"status code 429",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			var got message
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			n := New(Options{URL: server.URL})
+			err := n.Send(context.Background(), types.Entry{Level: logrus.ErrorLevel, Message: "message"}, types.FormatMessageArgs{Service: "api"})
+			if err != nil {
+				assert.Contains(t, err.Error(), test.want)
+				return
+			}
+			assert.Equal(t, test.want, err)
+			assert.Len(t, got.Embeds, 1)
+			assert.Equal(t, "message", got.Embeds[0].Description)
+		})
+	}
+}
+
+func TestNewEmbed(t *testing.T) {
+	// Built the same way as Logger.WithFields.
+	entry := types.Entry(*logrus.WithFields(logrus.Fields{
+		types.FieldKey: types.Fields{"key": "value"},
+	}).WithError(&errors.Error{Code: errors.INTERNAL, Message: "error message", Operation: "op", Err: errors.New("error")}))
+	entry.Level = logrus.ErrorLevel
+	entry.Message = "message"
+
+	t.Run("Fields", func(t *testing.T) {
+		got := newEmbed(entry, types.FormatMessageArgs{Service: "api", Version: "v0.0.1"})
+		assert.Equal(t, "ERROR | api", got.Title)
+		assert.Equal(t, "message", got.Description)
+		assert.Equal(t, hooks.ColourError, got.Color)
+		assert.Equal(t, "api v0.0.1", got.Footer.Text)
+
+		fields := map[string]string{}
+		for _, f := range got.Fields {
+			fields[f.Name] = f.Value
+		}
+		assert.Equal(t, errors.INTERNAL, fields["Code"])
+		assert.Equal(t, "op", fields["Operation"])
+		assert.Equal(t, "error message", fields["Message"])
+		assert.Equal(t, "error", fields["Error"])
+		assert.Equal(t, "value", fields["key"])
+	})
+
+	t.Run("Formatter", func(t *testing.T) {
+		got := newEmbed(types.Entry{Level: logrus.WarnLevel}, types.FormatMessageArgs{
+			Formatter: func(entry types.Entry, args types.FormatMessageArgs) string {
+				return "formatted"
+			},
+		})
+		assert.Equal(t, "formatted", got.Description)
+		assert.Equal(t, hooks.ColourWarn, got.Color)
+		assert.Nil(t, got.Footer)
+	})
+
+	t.Run("Max Fields", func(t *testing.T) {
+		fields := types.Fields{}
+		for i := 0; i < 30; i++ {
+			fields[string(rune('a'+i))] = i
+		}
+		got := newEmbed(types.Entry{Data: map[string]any{types.FieldKey: fields}}, types.FormatMessageArgs{})
+		assert.Len(t, got.Fields, maxFields)
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		fields := types.Fields{}
+		for i := 0; i < 30; i++ {
+			fields[strings.Repeat("k", 300)+string(rune('a'+i))] = strings.Repeat("v", 2000)
+		}
+		got := newEmbed(types.Entry{
+			Level:   logrus.InfoLevel,
+			Message: strings.Repeat("m", 5000),
+			Data:    map[string]any{types.FieldKey: fields},
+		}, types.FormatMessageArgs{Service: strings.Repeat("s", 3000)})
+
+		assert.Equal(t, maxTitle, utf8.RuneCountInString(got.Title))
+		assert.Equal(t, maxFooter, utf8.RuneCountInString(got.Footer.Text))
+		assert.NotEmpty(t, got.Fields)
+		for _, f := range got.Fields {
+			assert.LessOrEqual(t, utf8.RuneCountInString(f.Name), maxFieldName)
+			assert.LessOrEqual(t, utf8.RuneCountInString(f.Value), maxFieldValue)
+		}
+		assert.LessOrEqual(t, got.length(), maxTotal)
+	})
+}
@@ -0,0 +1,342 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// DefaultSubject is the default template used for the
+	// subject of the email.
+	DefaultSubject = "[{{ .Prefix }}] {{ .Level }} in {{ .Service }}{{ if .Message }}: {{ .Message }}{{ end }}"
+	// DefaultTimeout is the default amount of time to wait
+	// for the email to be sent.
+	DefaultTimeout = time.Second * 10
+)
+
+type (
+	// Notifier represents the email notifier for log
+	// entries.
+	Notifier struct {
+		subject *template.Template
+		options Options
+	}
+	// Options defines the configuration needed to send logs
+	// via SMTP.
+	Options struct {
+		Host     string
+		Port     int
+		Username string
+		Password string
+		From     string
+		To       []string
+		// Subject is a text/template used to render the
+		// subject, the Data is passed to it. Defaults to
+		// DefaultSubject.
+		Subject string
+		// Timeout defaults to DefaultTimeout.
+		Timeout time.Duration
+		// TLSConfig is used when the server supports STARTTLS,
+		// the ServerName defaults to the Host.
+		TLSConfig *tls.Config
+	}
+	// Data is the data used to render the subject and HTML
+	// part of the email.
+	Data struct {
+		Service string
+		Version string
+		Prefix  string
+		Level   string
+		Time    string
+		Message string
+		// Code and Operation are set if an error is
+		// attached to the entry.
+		Code      string
+		Operation string
+		Facts     []Fact
+		Fields    []Fact
+	}
+	// Fact is a single title and value pair shown within
+	// the HTML part.
+	Fact struct {
+		Title string
+		Value string
+	}
+)
+
+// New creates a new email notifier, an error is returned
+// if the options are incomplete or the subject template
+// is invalid.
+func New(opts Options) (*Notifier, error) {
+	if opts.Host == "" {
+		return nil, errors.New("email host cannot be empty")
+	}
+	if opts.From == "" {
+		return nil, errors.New("email from address cannot be empty")
+	}
+	if len(opts.To) == 0 {
+		return nil, errors.New("email must have at least one recipient")
+	}
+	if opts.Port == 0 {
+		opts.Port = 587
+	}
+	if opts.Subject == "" {
+		opts.Subject = DefaultSubject
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	subject, err := template.New("subject").Parse(opts.Subject)
+	if err != nil {
+		return nil, err
+	}
+	return &Notifier{
+		subject: subject,
+		options: opts,
+	}, nil
+}
+
+// Send renders the entry and emails it to the recipients.
+func (n *Notifier) Send(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+	msg, err := n.message(entry, args)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.options.Timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(n.options.Host, strconv.Itoa(n.options.Port))
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, n.options.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err = c.StartTLS(n.tlsConfig())
+		if err != nil {
+			return err
+		}
+	}
+	if n.options.Username != "" {
+		err = c.Auth(smtp.PlainAuth("", n.options.Username, n.options.Password, n.options.Host))
+		if err != nil {
+			return err
+		}
+	}
+	err = c.Mail(n.options.From)
+	if err != nil {
+		return err
+	}
+	for _, to := range n.options.To {
+		err = c.Rcpt(to)
+		if err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(msg)
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// tlsConfig returns the configuration used to upgrade the
+// connection with STARTTLS.
+func (n *Notifier) tlsConfig() *tls.Config {
+	cfg := &tls.Config{}
+	if n.options.TLSConfig != nil {
+		cfg = n.options.TLSConfig.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = n.options.Host
+	}
+	return cfg
+}
+
+// message creates the MIME message for the entry with a
+// plain text part, formatted by the notifier's formatter,
+// and an HTML part.
+func (n *Notifier) message(entry types.Entry, args types.FormatMessageArgs) ([]byte, error) {
+	data := newData(entry, args)
+
+	subject := &bytes.Buffer{}
+	err := n.subject.Execute(subject, data)
+	if err != nil {
+		return nil, err
+	}
+
+	html := &bytes.Buffer{}
+	err = htmlTemplate.Execute(html, data)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	mw := multipart.NewWriter(buf)
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", n.options.From)
+	header.Set("To", strings.Join(n.options.To, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(subject.String()), " ")))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("MIME-Version", "1.0")
+	header.Set("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.WriteString(k + ": " + header.Get(k) + "\r\n")
+	}
+	buf.WriteString("\r\n")
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", args.Message(entry)},
+		{"text/html; charset=utf-8", html.String()},
+	}
+	for _, p := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		_, err = qw.Write([]byte(p.body))
+		if err != nil {
+			return nil, err
+		}
+		err = qw.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = mw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// newData creates the template data for the entry.
+func newData(entry types.Entry, args types.FormatMessageArgs) Data {
+	d := Data{
+		Service: args.Service,
+		Version: args.Version,
+		Prefix:  strings.ToUpper(args.Prefix),
+		Level:   strings.ToUpper(entry.Level.String()),
+		Time:    entry.Time.Format(time.RFC1123),
+		Message: entry.Message,
+	}
+
+	d.Facts = append(d.Facts, Fact{"Level", d.Level}, Fact{"Time", d.Time})
+	if entry.Message != "" {
+		d.Facts = append(d.Facts, Fact{"Message", entry.Message})
+	}
+	if e := entry.Error(); e != nil {
+		d.Code = e.Code
+		d.Operation = e.Operation
+		d.Facts = append(d.Facts,
+			Fact{"Code", e.Code},
+			Fact{"Error Message", e.Message},
+			Fact{"Operation", e.Operation},
+		)
+		if e.Err != nil {
+			d.Facts = append(d.Facts, Fact{"Error", e.Err.Error()})
+		}
+		d.Facts = append(d.Facts, Fact{"Fileline", e.FileLine()})
+	}
+
+	fields := entry.Fields()
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		d.Fields = append(d.Fields, Fact{k, fmt.Sprintf("%v", fields[k])})
+	}
+
+	return d
+}
+
+// htmlTemplate is the template used for the HTML part of
+// the email.
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+<h2>{{ .Service }}{{ if .Version }} {{ .Version }}{{ end }}</h2>
+<p>Error detected in {{ .Prefix }}, please see the information below for more details.</p>
+<table cellpadding="4">
+{{- range .Facts }}
+<tr><th align="left">{{ .Title }}</th><td>{{ .Value }}</td></tr>
+{{- end }}
+</table>
+{{- if .Fields }}
+<h3>Fields</h3>
+<table cellpadding="4">
+{{- range .Fields }}
+<tr><th align="left">{{ .Title }}</th><td>{{ .Value }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+</body>
+</html>
+`))
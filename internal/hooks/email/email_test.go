@@ -0,0 +1,327 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is a minimal SMTP server that records the
+// last message it received.
+type fakeSMTP struct {
+	listener net.Listener
+	cert     tls.Certificate
+	pool     *x509.CertPool
+	tls      bool
+	auth     string
+	from     string
+	to       []string
+	data     string
+	done     chan struct{}
+}
+
+// newFakeSMTP starts a fake SMTP server on localhost that
+// accepts a single connection and supports STARTTLS with
+// a self-signed certificate.
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, pool := newCertificate(t)
+	s := &fakeSMTP{listener: l, cert: cert, pool: pool, done: make(chan struct{})}
+	go s.serve()
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+	return s
+}
+
+// newCertificate creates a self-signed certificate for
+// localhost and a pool that trusts it.
+func newCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+// port returns the port the server is listening on.
+func (s *fakeSMTP) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// serve handles a single SMTP session.
+func (s *fakeSMTP) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	write := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+
+	write("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			write("250-localhost")
+			if !s.tls {
+				write("250-STARTTLS")
+			}
+			write("250 AUTH PLAIN")
+		case "STARTTLS":
+			write("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{s.cert}})
+			if tlsConn.Handshake() != nil {
+				return
+			}
+			conn = tlsConn
+			r = bufio.NewReader(conn)
+			s.tls = true
+		case "AUTH":
+			s.auth = line
+			write("235 Authentication successful")
+		case "MAIL":
+			s.from = line
+			write("250 OK")
+		case "RCPT":
+			s.to = append(s.to, line)
+			write("250 OK")
+		case "DATA":
+			write("354 Start mail input")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.data = data.String()
+			write("250 OK")
+		case "QUIT":
+			write("221 Bye")
+			return
+		default:
+			write("502 Command not implemented")
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	tt := map[string]struct {
+		input Options
+		want  any
+	}{
+		"Success": {
+			Options{Host: "localhost", From: "from@example.com", To: []string{"to@example.com"}},
+			nil,
+		},
+		"No Host": {
+			Options{},
+			"host cannot be empty",
+		},
+		"No From": {
+			Options{Host: "localhost"},
+			"from address cannot be empty",
+		},
+		"No Recipients": {
+			Options{Host: "localhost", From: "from@example.com"},
+			"at least one recipient",
+		},
+		"Bad Subject": {
+			Options{Host: "localhost", From: "from@example.com", To: []string{"to@example.com"}, Subject: "{{ .Level "},
+			"unclosed action",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := New(test.input)
+			if err != nil {
+				assert.Contains(t, err.Error(), test.want)
+				return
+			}
+			assert.Equal(t, 587, got.options.Port)
+			assert.Equal(t, DefaultTimeout, got.options.Timeout)
+			assert.Equal(t, DefaultSubject, got.options.Subject)
+		})
+	}
+}
+
+func TestNotifier_Send(t *testing.T) {
+	server := newFakeSMTP(t)
+
+	n, err := New(Options{
+		Host:     "localhost",
+		Port:     server.port(),
+		Username: "user",
+		Password: "password",
+		From:     "from@example.com",
+		To:       []string{"a@example.com", "b@example.com"},
+		// The ServerName is set from the Host.
+		TLSConfig: &tls.Config{RootCAs: server.pool},
+	})
+	assert.NoError(t, err)
+
+	entry := types.Entry{
+		Level:   logrus.ErrorLevel,
+		Message: "message",
+		Data: map[string]any{
+			types.ErrorKey: errors.NewInternal(errors.New("error"), "message", "op"),
+			types.FieldKey: map[string]any{"key": "<value>"},
+		},
+	}
+	args := types.FormatMessageArgs{Service: "api", Prefix: "app", Version: "v0.0.1"}
+
+	err = n.Send(context.Background(), entry, args)
+	assert.NoError(t, err)
+	<-server.done
+
+	assert.True(t, server.tls)
+	assert.NotEmpty(t, server.auth)
+	assert.Equal(t, "MAIL FROM:<from@example.com>", strings.SplitN(server.from, " BODY", 2)[0])
+	assert.Equal(t, []string{"RCPT TO:<a@example.com>", "RCPT TO:<b@example.com>"}, server.to)
+
+	msg, err := mail.ReadMessage(strings.NewReader(server.data))
+	assert.NoError(t, err)
+	assert.Equal(t, "[APP] ERROR in api: message", msg.Header.Get("Subject"))
+	assert.Equal(t, "a@example.com, b@example.com", msg.Header.Get("To"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	parts := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		b, err := io.ReadAll(p)
+		assert.NoError(t, err)
+		ct, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		parts[ct] = string(b)
+	}
+
+	assert.Equal(t, types.DefaultFormatMessageFn(entry, args), strings.ReplaceAll(parts["text/plain"], "\r\n", "\n"))
+	assert.Contains(t, parts["text/html"], "<h2>api v0.0.1</h2>")
+	assert.Contains(t, parts["text/html"], "&lt;value&gt;")
+	assert.Contains(t, parts["text/html"], "op")
+}
+
+func TestNotifier_SendUntrusted(t *testing.T) {
+	server := newFakeSMTP(t)
+
+	n, err := New(Options{Host: "localhost", Port: server.port(), From: "from@example.com", To: []string{"to@example.com"}})
+	assert.NoError(t, err)
+
+	err = n.Send(context.Background(), types.Entry{}, types.FormatMessageArgs{})
+	assert.ErrorContains(t, err, "certificate")
+}
+
+func TestNotifier_SendError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	assert.NoError(t, l.Close())
+
+	n, err := New(Options{Host: "127.0.0.1", Port: port, From: "from@example.com", To: []string{"to@example.com"}})
+	assert.NoError(t, err)
+
+	err = n.Send(context.Background(), types.Entry{}, types.FormatMessageArgs{})
+	assert.ErrorContains(t, err, strconv.Itoa(port))
+}
+
+func TestNotifier_Subject(t *testing.T) {
+	n, err := New(Options{
+		Host:    "localhost",
+		From:    "from@example.com",
+		To:      []string{"to@example.com"},
+		Subject: "{{ .Code }} - {{ .Operation }}",
+	})
+	assert.NoError(t, err)
+
+	msg, err := n.message(types.Entry{
+		Data: map[string]any{
+			types.ErrorKey: errors.NewInternal(errors.New("error"), "message", "op"),
+		},
+	}, types.FormatMessageArgs{})
+	assert.NoError(t, err)
+
+	m, err := mail.ReadMessage(strings.NewReader(string(msg)))
+	assert.NoError(t, err)
+	assert.Equal(t, "internal - op", m.Header.Get("Subject"))
+}
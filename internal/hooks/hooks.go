@@ -0,0 +1,60 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hooks
+
+import (
+	"github.com/sirupsen/logrus"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultTimeout is the default amount of time to wait
+// for a remote service to respond.
+const DefaultTimeout = time.Second * 10
+
+// Colours shown alongside entries for each level, such as
+// Slack attachments and Discord embeds.
+const (
+	ColourError = 0xE74C3C
+	ColourWarn  = 0xF1C40F
+	ColourInfo  = 0x3498DB
+	ColourDebug = 0x95A5A6
+)
+
+// Colour returns the colour for the level as an RGB
+// integer.
+func Colour(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel:
+		return ColourError
+	case logrus.WarnLevel:
+		return ColourWarn
+	case logrus.InfoLevel:
+		return ColourInfo
+	default:
+		return ColourDebug
+	}
+}
+
+// Truncate shortens the string to max characters, ending
+// it with an ellipsis if it has been cut.
+func Truncate(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max-1]) + "…"
+}
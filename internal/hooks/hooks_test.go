@@ -14,34 +14,45 @@
 package hooks
 
 import (
-	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
-func TestGetMessage(t *testing.T) {
-	entry := types.Entry{Message: "message"}
+func TestColour(t *testing.T) {
+	tt := map[logrus.Level]int{
+		logrus.PanicLevel: ColourError,
+		logrus.FatalLevel: ColourError,
+		logrus.ErrorLevel: ColourError,
+		logrus.WarnLevel:  ColourWarn,
+		logrus.InfoLevel:  ColourInfo,
+		logrus.DebugLevel: ColourDebug,
+		logrus.TraceLevel: ColourDebug,
+	}
+
+	for level, want := range tt {
+		t.Run(level.String(), func(t *testing.T) {
+			assert.Equal(t, want, Colour(level))
+		})
+	}
+}
 
+func TestTruncate(t *testing.T) {
 	tt := map[string]struct {
-		formatter types.FormatMessageFunc
-		want      any
+		input string
+		max   int
+		want  string
 	}{
-		"Nil Format": {
-			nil,
-			"message",
-		},
-		"With Format": {
-			func(entry types.Entry, args types.FormatMessageArgs) string {
-				return "hello"
-			},
-			"hello",
-		},
+		"Short":    {"hello", 10, "hello"},
+		"Exact":    {"hello", 5, "hello"},
+		"Truncate": {"hello world", 5, "hell…"},
+		"Runes":    {"héllo wörld", 3, "hé…"},
+		"Zero":     {"hello", 0, ""},
 	}
 
 	for name, test := range tt {
 		t.Run(name, func(t *testing.T) {
-			got := GetMessage(entry, types.FormatMessageArgs{}, test.formatter)
-			assert.Contains(t, got, test.want)
+			assert.Equal(t, test.want, Truncate(test.input, test.max))
 		})
 	}
 }
@@ -0,0 +1,213 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/hooks"
+	"github.com/ainsleyclark/logger/internal/httputil"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the default URL of the PagerDuty
+	// Events API.
+	DefaultBaseURL = "https://events.pagerduty.com"
+	// maxSummary is the maximum length of the summary
+	// accepted by PagerDuty.
+	maxSummary = 1024
+)
+
+type (
+	// Notifier represents the PagerDuty notifier for log
+	// entries.
+	Notifier struct {
+		client  *http.Client
+		options Options
+	}
+	// Options defines the configuration needed to send
+	// events to the PagerDuty Events API v2.
+	Options struct {
+		RoutingKey string
+		// BaseURL defaults to DefaultBaseURL.
+		BaseURL string
+		// Source is the affected system, defaults to the
+		// service name.
+		Source string
+		// Timeout defaults to hooks.DefaultTimeout.
+		Timeout time.Duration
+	}
+	// Event is the body sent to the Events API, see
+	// https://developer.pagerduty.com/docs/events-api-v2/trigger-events
+	Event struct {
+		RoutingKey  string  `json:"routing_key"`
+		EventAction string  `json:"event_action"`
+		DedupKey    string  `json:"dedup_key,omitempty"`
+		Payload     Payload `json:"payload"`
+	}
+	// Payload is the information about the event.
+	Payload struct {
+		Summary       string         `json:"summary"`
+		Source        string         `json:"source"`
+		Severity      string         `json:"severity"`
+		Timestamp     string         `json:"timestamp,omitempty"`
+		Component     string         `json:"component,omitempty"`
+		Class         string         `json:"class,omitempty"`
+		CustomDetails map[string]any `json:"custom_details,omitempty"`
+	}
+)
+
+// New creates a new PagerDuty notifier.
+func New(opts Options) (*Notifier, error) {
+	if opts.RoutingKey == "" {
+		return nil, errors.New("pagerduty routing key cannot be empty")
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultBaseURL
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = hooks.DefaultTimeout
+	}
+	return &Notifier{
+		client:  &http.Client{Timeout: opts.Timeout},
+		options: opts,
+	}, nil
+}
+
+// Send triggers a PagerDuty event for the entry.
+func (n *Notifier) Send(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+	body, err := json.Marshal(n.event(entry, args))
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimSuffix(n.options.BaseURL, "/") + "/v2/enqueue"
+	return httputil.PostJSON(ctx, n.client, url, body, nil, "pagerduty")
+}
+
+// event creates the trigger event for the entry. The
+// summary is the formatted message if a formatter has
+// been set.
+func (n *Notifier) event(entry types.Entry, args types.FormatMessageArgs) Event {
+	source := n.options.Source
+	if source == "" {
+		source = args.Service
+	}
+
+	e := Event{
+		RoutingKey:  n.options.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    DedupKey(entry),
+		Payload: Payload{
+			Summary:       hooks.Truncate(summary(entry, args), maxSummary),
+			Source:        source,
+			Severity:      Severity(entry.Level),
+			Component:     args.Service,
+			CustomDetails: details(entry, args),
+		},
+	}
+	if !entry.Time.IsZero() {
+		e.Payload.Timestamp = entry.Time.Format(time.RFC3339)
+	}
+	if err := entry.Error(); err != nil {
+		e.Payload.Class = err.Code
+	}
+
+	return e
+}
+
+// Severity maps the logrus level to a PagerDuty severity.
+func Severity(level logrus.Level) string {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return "critical"
+	case logrus.ErrorLevel:
+		return "error"
+	case logrus.WarnLevel:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// DedupKey returns the key used to group events into a
+// single incident, it's made up of the error code and
+// operation. An empty string is returned if there is
+// no error attached to the entry, in which case
+// PagerDuty generates one.
+func DedupKey(entry types.Entry) string {
+	err := entry.Error()
+	if err == nil || (err.Code == "" && err.Operation == "") {
+		return ""
+	}
+	return err.Code + ":" + err.Operation
+}
+
+// summary returns the summary of the event.
+func summary(entry types.Entry, args types.FormatMessageArgs) string {
+	if args.Formatter != nil {
+		return args.Message(entry)
+	}
+	msg := entry.Message
+	if err := entry.Error(); err != nil {
+		msg = err.Message
+		if msg == "" && err.Err != nil {
+			msg = err.Err.Error()
+		}
+		if err.Operation != "" {
+			msg = err.Operation + ": " + msg
+		}
+		if err.Code != "" {
+			msg = "<" + err.Code + "> " + msg
+		}
+	}
+	if msg == "" {
+		msg = strings.ToUpper(entry.Level.String())
+	}
+	if args.Service == "" {
+		return msg
+	}
+	return "[" + args.Service + "] " + msg
+}
+
+// details returns the custom details of the event, made
+// up of the entry fields and the error.
+func details(entry types.Entry, args types.FormatMessageArgs) map[string]any {
+	d := map[string]any{}
+	for k, v := range entry.Fields() {
+		d[k] = v
+	}
+	if args.Version != "" {
+		d["version"] = args.Version
+	}
+	if err := entry.Error(); err != nil {
+		d["code"] = err.Code
+		d["operation"] = err.Operation
+		d["message"] = err.Message
+		d["fileline"] = err.FileLine()
+		if err.Err != nil {
+			d["error"] = err.Err.Error()
+		}
+	}
+	if len(d) == 0 {
+		return nil
+	}
+	return d
+}
@@ -0,0 +1,168 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/hooks"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestNew(t *testing.T) {
+	got, err := New(Options{RoutingKey: "key"})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultBaseURL, got.options.BaseURL)
+	assert.Equal(t, hooks.DefaultTimeout, got.client.Timeout)
+
+	_, err = New(Options{})
+	assert.ErrorContains(t, err, "routing key cannot be empty")
+}
+
+func TestNotifier_Send(t *testing.T) {
+	entry := types.Entry{
+		Level: logrus.ErrorLevel,
+		Time:  time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Data: map[string]any{
+			types.ErrorKey: errors.NewInternal(errors.New("error"), "message", "op"),
+			types.FieldKey: types.Fields{"user": 1},
+		},
+	}
+
+	tt := map[string]struct {
+		status int
+		want   any
+	}{
+		"Success": {
+			http.StatusAccepted,
+			nil,
+		},
+		"Bad Status": {
+			http.StatusBadRequest,
+			"status code 400",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			var (
+				got  Event
+				path string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			n, err := New(Options{RoutingKey: "key", BaseURL: server.URL + "/"})
+			assert.NoError(t, err)
+
+			err = n.Send(context.Background(), entry, types.FormatMessageArgs{Service: "api", Version: "v0.0.1"})
+			if err != nil {
+				assert.Contains(t, err.Error(), test.want)
+				return
+			}
+			assert.Equal(t, test.want, err)
+			assert.Equal(t, "/v2/enqueue", path)
+			assert.Equal(t, "key", got.RoutingKey)
+			assert.Equal(t, "trigger", got.EventAction)
+			assert.Equal(t, "internal:op", got.DedupKey)
+			assert.Equal(t, "[api] <internal> op: message", got.Payload.Summary)
+			assert.Equal(t, "api", got.Payload.Source)
+			assert.Equal(t, "error", got.Payload.Severity)
+			assert.Equal(t, "2022-01-01T00:00:00Z", got.Payload.Timestamp)
+			assert.Equal(t, "internal", got.Payload.Class)
+			assert.Equal(t, float64(1), got.Payload.CustomDetails["user"])
+			assert.Equal(t, "v0.0.1", got.Payload.CustomDetails["version"])
+			assert.Equal(t, "error", got.Payload.CustomDetails["error"])
+		})
+	}
+}
+
+func TestNotifier_Event(t *testing.T) {
+	n, err := New(Options{RoutingKey: "key", Source: "host"})
+	assert.NoError(t, err)
+
+	t.Run("No Error", func(t *testing.T) {
+		got := n.event(types.Entry{Level: logrus.WarnLevel, Message: "message"}, types.FormatMessageArgs{})
+		assert.Equal(t, "", got.DedupKey)
+		assert.Equal(t, "message", got.Payload.Summary)
+		assert.Equal(t, "host", got.Payload.Source)
+		assert.Equal(t, "warning", got.Payload.Severity)
+		assert.Nil(t, got.Payload.CustomDetails)
+	})
+
+	t.Run("Formatter", func(t *testing.T) {
+		got := n.event(types.Entry{}, types.FormatMessageArgs{
+			Formatter: func(entry types.Entry, args types.FormatMessageArgs) string {
+				return strings.Repeat("a", 2000)
+			},
+		})
+		assert.Equal(t, maxSummary, utf8.RuneCountInString(got.Payload.Summary))
+		assert.True(t, strings.HasSuffix(got.Payload.Summary, "…"))
+	})
+}
+
+func TestSeverity(t *testing.T) {
+	tt := map[logrus.Level]string{
+		logrus.PanicLevel: "critical",
+		logrus.FatalLevel: "critical",
+		logrus.ErrorLevel: "error",
+		logrus.WarnLevel:  "warning",
+		logrus.InfoLevel:  "info",
+		logrus.DebugLevel: "info",
+		logrus.TraceLevel: "info",
+	}
+	for level, want := range tt {
+		t.Run(level.String(), func(t *testing.T) {
+			assert.Equal(t, want, Severity(level))
+		})
+	}
+}
+
+func TestDedupKey(t *testing.T) {
+	tt := map[string]struct {
+		input types.Entry
+		want  string
+	}{
+		"No Error": {
+			types.Entry{},
+			"",
+		},
+		"Code And Operation": {
+			types.Entry{Data: map[string]any{types.ErrorKey: &errors.Error{Code: "code", Operation: "op"}}},
+			"code:op",
+		},
+		"Empty": {
+			types.Entry{Data: map[string]any{types.ErrorKey: &errors.Error{Message: "message"}}},
+			"",
+		},
+	}
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, DedupKey(test.input))
+		})
+	}
+}
@@ -14,68 +14,234 @@
 package slack
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/ainsleyclark/logger/internal/hooks"
+	"github.com/ainsleyclark/logger/internal/retry"
 	"github.com/ainsleyclark/logger/types"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
-	"log"
+	"sort"
+	"strings"
+	"time"
 )
 
-// NewHook creates a new Workplace hook.
-// Returns an error if the client could not be created.
-func NewHook(opts Options) *Hook {
-	return &Hook{
-		sendFunc:  slack.New(opts.Token).PostMessage,
-		options:   opts,
-		LogLevels: logrus.AllLevels,
+const (
+	// maxHeader is the maximum length of the text within
+	// a header block.
+	maxHeader = 150
+	// maxSection is the maximum length of the text within
+	// a section block.
+	maxSection = 3000
+)
+
+// New creates a new Slack notifier. If a webhook URL is
+// set, messages are posted to the incoming webhook
+// instead of using the bot token.
+func New(opts Options) *Notifier {
+	client := slack.New(opts.Token)
+	return &Notifier{
+		sendFunc:    client.PostMessageContext,
+		updateFunc:  client.UpdateMessageContext,
+		webhookFunc: slack.PostWebhookContext,
+		options:     opts,
 	}
 }
 
 type (
-	// Hook represents the Slack hook notifier
-	// for log entries.
-	Hook struct {
-		sendFunc  func(channelID string, options ...slack.MsgOption) (string, string, error)
-		options   Options
-		LogLevels []logrus.Level
-	}
-	// Options defines the configuration needed to fire logs
-	// via the Workplace API.
+	// Notifier represents the Slack notifier for log
+	// entries.
+	Notifier struct {
+		sendFunc    sendSlackFunc
+		updateFunc  updateSlackFunc
+		webhookFunc sendWebhookFunc
+		options     Options
+		threads     threads
+	}
+	// Options defines the configuration needed to send logs
+	// via the Slack API.
 	Options struct {
-		Token         string
-		Channel       string
-		Args          types.FormatMessageArgs
-		FormatMessage types.FormatMessageFunc
+		Token   string
+		Channel string
+		// WebhookURL is the incoming webhook messages are
+		// posted to when there is no bot token.
+		WebhookURL string
+		// Blocks builds the Block Kit layout of the message,
+		// defaults to DefaultBlocks.
+		Blocks BlockBuilder
+		// ThreadWindow enables threading of repeated errors,
+		// entries with the same fingerprint are posted as
+		// replies to the first message until it hasn't
+		// been seen for the window. Threading isn't
+		// available with incoming webhooks.
+		ThreadWindow time.Duration
+		// OnError is called when the occurrence counter on a
+		// thread's parent message fails to be updated, the
+		// reply has already been posted so the error
+		// isn't returned from Send.
+		OnError func(err error)
 	}
+	// BlockBuilder is the function used to create the Block
+	// Kit blocks sent for an entry.
+	BlockBuilder func(entry types.Entry, args types.FormatMessageArgs) []slack.Block
 	// sendSlackFunc is the function used for sending to
-	// a slack channell.
-	sendSlackFunc func(channelID string, options ...slack.MsgOption) (string, string, error)
+	// a slack channel.
+	sendSlackFunc func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error)
+	// sendWebhookFunc is the function used for sending to
+	// an incoming webhook.
+	sendWebhookFunc func(ctx context.Context, url string, msg *slack.WebhookMessage) error
 )
 
-// Fire will be called when some logging function is
-// called with current hook. It will format log
-// entry to string and write it to
-// appropriate writer
-func (hook *Hook) Fire(entry *logrus.Entry) error {
-	go hook.process(types.Entry(*entry)) // This is already check for nil pointer.
-	return nil
+// Send builds the Block Kit message for the entry and posts
+// it to the Slack channel or incoming webhook. The
+// formatted message is used as the notification text.
+func (n *Notifier) Send(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+	if n.options.WebhookURL != "" {
+		return retryAfter(n.webhookFunc(ctx, n.options.WebhookURL, &slack.WebhookMessage{
+			Text:        args.Message(entry),
+			Attachments: []slack.Attachment{n.attachment(entry, args)},
+		}))
+	}
+	if n.options.ThreadWindow > 0 {
+		return retryAfter(n.sendThreaded(ctx, entry, args))
+	}
+	// Use the Slack client to send a message via the bot.
+	_, _, err := n.sendFunc(ctx, n.options.Channel, n.message(entry, args)...)
+	return retryAfter(err)
 }
 
-func (hook *Hook) process(entry types.Entry) {
-	// Create the Slack attachment that we will send to the channel
-	attachment := slack.Attachment{
-		Pretext: "Logger",
-		Text:    hooks.GetMessage(entry, hook.options.Args, hook.options.FormatMessage),
+// retryAfter marks Slack's rate limit errors with the time
+// to wait before retrying.
+func retryAfter(err error) error {
+	var rl *slack.RateLimitedError
+	if errors.As(err, &rl) {
+		return retry.After(err, rl.RetryAfter)
 	}
-	// Use the Slack client to send a message via the bot.
-	_, _, err := hook.sendFunc(hook.options.Channel, slack.MsgOptionAttachments(attachment))
-	if err != nil {
-		log.Println(err.Error()) // We can't use the standard logger as it may cause a loop.
+	return err
+}
+
+// message returns the options used to post the entry via
+// the bot.
+func (n *Notifier) message(entry types.Entry, args types.FormatMessageArgs) []slack.MsgOption {
+	return []slack.MsgOption{
+		slack.MsgOptionText(args.Message(entry), false),
+		slack.MsgOptionAttachments(n.attachment(entry, args)),
 	}
 }
 
-// Levels Define on which log levels this hook would
-// trigger.
-func (hook *Hook) Levels() []logrus.Level {
-	return hook.LogLevels
+// attachment creates the attachment for the entry, blocks
+// within an attachment are shown with a colour bar for
+// the level. Any extra blocks are added to the end.
+func (n *Notifier) attachment(entry types.Entry, args types.FormatMessageArgs, extra ...slack.Block) slack.Attachment {
+	builder := n.options.Blocks
+	if builder == nil {
+		builder = DefaultBlocks
+	}
+	return slack.Attachment{
+		Color:  Colour(entry.Level),
+		Blocks: slack.Blocks{BlockSet: append(builder(entry, args), extra...)},
+	}
+}
+
+// DefaultBlocks creates the default Block Kit layout for
+// the entry. A header with the service and version, a
+// section with the level and message, fields for the
+// error's code, operation and fileline, a context with
+// the timestamp and a code block of the entry's data.
+func DefaultBlocks(entry types.Entry, args types.FormatMessageArgs) []slack.Block {
+	title := args.Service
+	if title == "" {
+		title = args.Prefix
+	}
+	if args.Version != "" {
+		title += " v" + strings.TrimPrefix(args.Version, "v")
+	}
+
+	var blocks []slack.Block
+	if title != "" {
+		blocks = append(blocks, slack.NewHeaderBlock(
+			slack.NewTextBlockObject(slack.PlainTextType, hooks.Truncate(title, maxHeader), false, false),
+		))
+	}
+
+	text := fmt.Sprintf("%s *%s*", emoji(entry.Level), strings.ToUpper(entry.Level.String()))
+	if entry.Message != "" {
+		text += " " + entry.Message
+	}
+	blocks = append(blocks, slack.NewSectionBlock(
+		slack.NewTextBlockObject(slack.MarkdownType, hooks.Truncate(text, maxSection), false, false), nil, nil,
+	))
+
+	if err := entry.Error(); err != nil {
+		var fields []*slack.TextBlockObject
+		add := func(name, value string) {
+			if value == "" {
+				return
+			}
+			fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s*\n%s", name, value), false, false))
+		}
+		add("Code", err.Code)
+		add("Operation", err.Operation)
+		add("Message", err.Message)
+		if err.Err != nil {
+			add("Error", err.Err.Error())
+		}
+		add("Fileline", err.FileLine())
+		if len(fields) > 0 {
+			blocks = append(blocks, slack.NewSectionBlock(nil, fields, nil))
+		}
+	}
+
+	if !entry.Time.IsZero() {
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("<!date^%d^{date_short_pretty} at {time_secs}|%s>", entry.Time.Unix(), entry.Time.Format(time.RFC3339)), false, false),
+		))
+	}
+
+	if data := formatData(entry); data != "" {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, "```"+hooks.Truncate(data, maxSection-6)+"```", false, false), nil, nil,
+		))
+	}
+
+	return blocks
+}
+
+// Colour returns the colour of the attachment for the
+// level.
+func Colour(level logrus.Level) string {
+	return fmt.Sprintf("#%06X", hooks.Colour(level))
+}
+
+// emoji returns the emoji shown next to the level.
+func emoji(level logrus.Level) string {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel:
+		return ":red_circle:"
+	case logrus.WarnLevel:
+		return ":warning:"
+	case logrus.InfoLevel:
+		return ":large_blue_circle:"
+	default:
+		return ":white_circle:"
+	}
+}
+
+// formatData returns the entry's data, excluding the
+// error, as key value pairs sorted by key.
+func formatData(entry types.Entry) string {
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		if k == types.ErrorKey {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	buf := strings.Builder{}
+	for _, k := range keys {
+		buf.WriteString(fmt.Sprintf("%s: %v\n", k, entry.Data[k]))
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
 }
@@ -14,33 +14,27 @@
 package slack
 
 import (
-	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	pkgerrors "github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/retry"
 	"github.com/ainsleyclark/logger/types"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
-	"log"
-	"os"
 	"testing"
+	"time"
 )
 
-func TestNewHook(t *testing.T) {
-	got := NewHook(Options{})
+func TestNew(t *testing.T) {
+	got := New(Options{Token: "token", Channel: "channel"})
 	assert.NotNil(t, got.sendFunc)
-	assert.NotNil(t, got.options)
-	assert.Equal(t, logrus.AllLevels, got.LogLevels)
+	assert.NotNil(t, got.webhookFunc)
+	assert.Equal(t, "channel", got.options.Channel)
 }
 
-func TestHook_Fire(t *testing.T) {
-	h := Hook{sendFunc: func(channelID string, options ...slack.MsgOption) (string, string, error) {
-		return "", "", nil
-	}}
-	got := h.Fire(&logrus.Entry{})
-	assert.Nil(t, got)
-}
-
-func TestHook_Process(t *testing.T) {
+func TestNotifier_Send(t *testing.T) {
 	entry := types.Entry{
 		Message: "message",
 		Data: map[string]any{
@@ -54,13 +48,13 @@ func TestHook_Process(t *testing.T) {
 		want any
 	}{
 		"Success": {
-			func(channelID string, options ...slack.MsgOption) (string, string, error) {
+			func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
 				return "", "", nil
 			},
-			"",
+			nil,
 		},
 		"Error": {
-			func(channelID string, options ...slack.MsgOption) (string, string, error) {
+			func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
 				return "", "", errors.New("error")
 			},
 			"error",
@@ -69,23 +63,163 @@ func TestHook_Process(t *testing.T) {
 
 	for name, test := range tt {
 		t.Run(name, func(t *testing.T) {
-			var buf bytes.Buffer
-			log.SetOutput(&buf)
-			defer func() {
-				log.SetOutput(os.Stderr)
-			}()
-			h := Hook{
-				sendFunc:  test.mock,
-				LogLevels: logrus.AllLevels,
+			var channel string
+			n := Notifier{
+				sendFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+					channel = channelID
+					return test.mock(ctx, channelID, options...)
+				},
+				options: Options{Channel: "channel"},
+			}
+			err := n.Send(context.Background(), entry, types.FormatMessageArgs{})
+			assert.Equal(t, "channel", channel)
+			if err != nil {
+				assert.Contains(t, err.Error(), test.want)
+				return
+			}
+			assert.Equal(t, test.want, err)
+		})
+	}
+}
+
+func TestNotifier_SendWebhook(t *testing.T) {
+	tt := map[string]struct {
+		err  error
+		want any
+	}{
+		"Success": {
+			nil,
+			nil,
+		},
+		"Error": {
+			errors.New("error"),
+			"error",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			var (
+				url string
+				msg *slack.WebhookMessage
+			)
+			n := Notifier{
+				sendFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+					t.Fatal("bot token should not be used")
+					return "", "", nil
+				},
+				webhookFunc: func(ctx context.Context, u string, m *slack.WebhookMessage) error {
+					url, msg = u, m
+					return test.err
+				},
+				options: Options{WebhookURL: "https://hooks.slack.com/services/T/B/X"},
+			}
+			err := n.Send(context.Background(), types.Entry{Level: logrus.InfoLevel}, types.FormatMessageArgs{})
+			assert.Equal(t, "https://hooks.slack.com/services/T/B/X", url)
+			assert.Len(t, msg.Attachments, 1)
+			assert.NotEmpty(t, msg.Text)
+			if err != nil {
+				assert.Contains(t, err.Error(), test.want)
+				return
 			}
-			h.process(entry)
-			assert.Contains(t, buf.String(), test.want)
+			assert.Equal(t, test.want, err)
 		})
 	}
 }
 
-func TestHook_Levels(t *testing.T) {
-	h := Hook{LogLevels: logrus.AllLevels}
-	want := logrus.AllLevels
-	assert.Equal(t, want, h.Levels())
+func TestNotifier_SendBlocks(t *testing.T) {
+	entry := types.Entry{Level: logrus.ErrorLevel, Message: "message"}
+	args := types.FormatMessageArgs{
+		Formatter: func(entry types.Entry, args types.FormatMessageArgs) string {
+			return "formatted"
+		},
+	}
+
+	tt := map[string]struct {
+		builder BlockBuilder
+		want    int
+	}{
+		"Default": {
+			nil,
+			1,
+		},
+		"Custom": {
+			func(entry types.Entry, args types.FormatMessageArgs) []slack.Block {
+				return []slack.Block{slack.NewDividerBlock(), slack.NewDividerBlock()}
+			},
+			2,
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			var values map[string][]string
+			n := Notifier{
+				sendFunc: func(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+					_, v, err := slack.UnsafeApplyMsgOptions("token", channelID, "", options...)
+					values = v
+					return "", "", err
+				},
+				options: Options{Channel: "channel", Blocks: test.builder},
+			}
+			assert.NoError(t, n.Send(context.Background(), entry, args))
+			assert.Equal(t, "formatted", values["text"][0])
+
+			var attachments []slack.Attachment
+			assert.NoError(t, json.Unmarshal([]byte(values["attachments"][0]), &attachments))
+			assert.Len(t, attachments, 1)
+			assert.Equal(t, "#E74C3C", attachments[0].Color)
+			assert.Len(t, attachments[0].Blocks.BlockSet, test.want)
+		})
+	}
+}
+
+func TestDefaultBlocks(t *testing.T) {
+	entry := types.Entry{
+		Level:   logrus.WarnLevel,
+		Time:    time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Message: "message",
+		Data: map[string]any{
+			types.ErrorKey: &pkgerrors.Error{Code: "code", Operation: "op", Err: errors.New("error")},
+			"b":            2,
+			"a":            1,
+		},
+	}
+
+	got := DefaultBlocks(entry, types.FormatMessageArgs{Service: "api", Version: "v0.0.1"})
+	assert.Len(t, got, 5)
+
+	header := got[0].(*slack.HeaderBlock)
+	assert.Equal(t, "api v0.0.1", header.Text.Text)
+
+	section := got[1].(*slack.SectionBlock)
+	assert.Equal(t, ":warning: *WARNING* message", section.Text.Text)
+
+	fields := got[2].(*slack.SectionBlock)
+	assert.Len(t, fields.Fields, 3)
+	assert.Equal(t, "*Code*\ncode", fields.Fields[0].Text)
+	assert.Equal(t, "*Operation*\nop", fields.Fields[1].Text)
+
+	context := got[3].(*slack.ContextBlock)
+	assert.Len(t, context.ContextElements.Elements, 1)
+
+	data := got[4].(*slack.SectionBlock)
+	assert.Equal(t, "```a: 1\nb: 2```", data.Text.Text)
+}
+
+func TestDefaultBlocks_Minimal(t *testing.T) {
+	got := DefaultBlocks(types.Entry{Level: logrus.InfoLevel}, types.FormatMessageArgs{})
+	assert.Len(t, got, 1)
+	assert.Equal(t, ":large_blue_circle: *INFO*", got[0].(*slack.SectionBlock).Text.Text)
+}
+
+func TestRetryAfter(t *testing.T) {
+	err := retryAfter(&slack.RateLimitedError{RetryAfter: time.Second * 3})
+	got, ok := retry.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second*3, got)
+
+	_, ok = retry.RetryAfter(retryAfter(errors.New("error")))
+	assert.False(t, ok)
+	assert.Nil(t, retryAfter(nil))
 }
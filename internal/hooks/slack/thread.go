@@ -0,0 +1,115 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slack
+
+import (
+	"context"
+	"fmt"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/slack-go/slack"
+	"sync"
+	"time"
+)
+
+type (
+	// threads tracks the parent messages of repeated
+	// errors by the entry's fingerprint.
+	threads struct {
+		mtx     sync.Mutex
+		threads map[string]*thread
+	}
+	// thread is the parent message for a fingerprint.
+	thread struct {
+		mtx      sync.Mutex
+		channel  string
+		ts       string
+		entry    types.Entry
+		args     types.FormatMessageArgs
+		count    int
+		lastSeen time.Time
+	}
+	// updateSlackFunc is the function used for updating a
+	// message within a slack channel.
+	updateSlackFunc func(ctx context.Context, channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error)
+)
+
+// get returns the thread for the fingerprint, creating
+// one if it doesn't exist. Threads that haven't been
+// seen within the window are removed.
+func (t *threads) get(fingerprint string, window time.Duration) *thread {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if t.threads == nil {
+		t.threads = make(map[string]*thread)
+	}
+	now := time.Now()
+	for k, th := range t.threads {
+		th.mtx.Lock()
+		expired := th.ts != "" && now.Sub(th.lastSeen) > window
+		th.mtx.Unlock()
+		if expired {
+			delete(t.threads, k)
+		}
+	}
+	th, ok := t.threads[fingerprint]
+	if !ok {
+		th = &thread{}
+		t.threads[fingerprint] = th
+	}
+	return th
+}
+
+// sendThreaded posts the entry as a new message, or as a
+// reply to the message of a previous occurrence, in which
+// case the occurrence counter on the parent is updated.
+// Updating the parent is best effort, once the reply has
+// been posted any error is passed to OnError so that
+// the entry isn't delivered again.
+func (n *Notifier) sendThreaded(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+	th := n.threads.get(entry.Fingerprint(), n.options.ThreadWindow)
+	th.mtx.Lock()
+	defer th.mtx.Unlock()
+
+	if th.ts == "" {
+		channel, ts, err := n.sendFunc(ctx, n.options.Channel, n.message(entry, args)...)
+		if err != nil {
+			return err
+		}
+		th.channel, th.ts = channel, ts
+		th.entry, th.args = entry, args
+		th.count = 1
+		th.lastSeen = time.Now()
+		return nil
+	}
+
+	_, _, err := n.sendFunc(ctx, th.channel, append(n.message(entry, args), slack.MsgOptionTS(th.ts))...)
+	if err != nil {
+		return err
+	}
+	th.count++
+	th.lastSeen = time.Now()
+
+	// Add the counter to the parent message.
+	counter := slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType,
+		fmt.Sprintf(":repeat: Occurred *%d* times, last seen <!date^%d^{date_short_pretty} at {time_secs}|%s>",
+			th.count, th.lastSeen.Unix(), th.lastSeen.Format(time.RFC3339)), false, false))
+	_, _, _, err = n.updateFunc(ctx, th.channel, th.ts,
+		slack.MsgOptionText(th.args.Message(th.entry), false),
+		slack.MsgOptionAttachments(n.attachment(th.entry, th.args, counter)),
+	)
+	if err != nil && n.options.OnError != nil {
+		n.options.OnError(fmt.Errorf("updating slack thread %s: %w", th.ts, err))
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	pkgerrors "github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSlack records the messages posted and updated.
+type fakeSlack struct {
+	posts     []map[string][]string
+	updates   []map[string][]string
+	err       error
+	updateErr error
+}
+
+func (f *fakeSlack) post(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	if f.err != nil {
+		return "", "", f.err
+	}
+	_, v, _ := slack.UnsafeApplyMsgOptions("token", channelID, "", options...)
+	f.posts = append(f.posts, v)
+	return channelID, "ts-" + string(rune('0'+len(f.posts))), nil
+}
+
+func (f *fakeSlack) update(ctx context.Context, channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	if f.updateErr != nil {
+		return "", "", "", f.updateErr
+	}
+	_, v, _ := slack.UnsafeApplyMsgOptions("token", channelID, "", options...)
+	v["ts"] = []string{timestamp}
+	f.updates = append(f.updates, v)
+	return channelID, timestamp, "", nil
+}
+
+func TestNotifier_SendThreaded(t *testing.T) {
+	entry := func(msg string) types.Entry {
+		return types.Entry{Data: map[string]any{types.ErrorKey: pkgerrors.NewInternal(errors.New("error"), msg, "op")}}
+	}
+
+	f := &fakeSlack{}
+	n := Notifier{
+		sendFunc:   f.post,
+		updateFunc: f.update,
+		options:    Options{Channel: "channel", ThreadWindow: time.Hour},
+	}
+	ctx := context.Background()
+
+	assert.NoError(t, n.Send(ctx, entry("message"), types.FormatMessageArgs{}))
+	assert.NoError(t, n.Send(ctx, entry("message"), types.FormatMessageArgs{}))
+	assert.NoError(t, n.Send(ctx, entry("message"), types.FormatMessageArgs{}))
+	assert.NoError(t, n.Send(ctx, entry("other"), types.FormatMessageArgs{}))
+
+	assert.Len(t, f.posts, 4)
+	assert.Empty(t, f.posts[0]["thread_ts"])
+	assert.Equal(t, []string{"ts-1"}, f.posts[1]["thread_ts"])
+	assert.Equal(t, []string{"ts-1"}, f.posts[2]["thread_ts"])
+	assert.Empty(t, f.posts[3]["thread_ts"])
+
+	assert.Len(t, f.updates, 2)
+	assert.Equal(t, []string{"ts-1"}, f.updates[1]["ts"])
+
+	var attachments []slack.Attachment
+	assert.NoError(t, json.Unmarshal([]byte(f.updates[1]["attachments"][0]), &attachments))
+	blocks := attachments[0].Blocks.BlockSet
+	counter := blocks[len(blocks)-1].(*slack.ContextBlock)
+	text := counter.ContextElements.Elements[0].(*slack.TextBlockObject).Text
+	assert.True(t, strings.HasPrefix(text, ":repeat: Occurred *3* times"), text)
+}
+
+func TestNotifier_SendThreadedExpired(t *testing.T) {
+	f := &fakeSlack{}
+	n := Notifier{
+		sendFunc:   f.post,
+		updateFunc: f.update,
+		options:    Options{Channel: "channel", ThreadWindow: time.Millisecond},
+	}
+	ctx := context.Background()
+
+	assert.NoError(t, n.Send(ctx, types.Entry{Message: "message"}, types.FormatMessageArgs{}))
+	time.Sleep(time.Millisecond * 5)
+	assert.NoError(t, n.Send(ctx, types.Entry{Message: "message"}, types.FormatMessageArgs{}))
+
+	assert.Len(t, f.posts, 2)
+	assert.Empty(t, f.posts[1]["thread_ts"])
+	assert.Empty(t, f.updates)
+}
+
+func TestNotifier_SendThreadedError(t *testing.T) {
+	f := &fakeSlack{err: errors.New("error")}
+	n := Notifier{
+		sendFunc:   f.post,
+		updateFunc: f.update,
+		options:    Options{Channel: "channel", ThreadWindow: time.Hour},
+	}
+	ctx := context.Background()
+
+	assert.Error(t, n.Send(ctx, types.Entry{Message: "message"}, types.FormatMessageArgs{}))
+
+	// The parent is posted once the error has cleared.
+	f.err = nil
+	assert.NoError(t, n.Send(ctx, types.Entry{Message: "message"}, types.FormatMessageArgs{}))
+	assert.Len(t, f.posts, 1)
+	assert.Empty(t, f.posts[0]["thread_ts"])
+}
+
+func TestNotifier_SendThreadedUpdateError(t *testing.T) {
+	var errs []error
+	f := &fakeSlack{updateErr: errors.New("update error")}
+	n := Notifier{
+		sendFunc:   f.post,
+		updateFunc: f.update,
+		options: Options{Channel: "channel", ThreadWindow: time.Hour, OnError: func(err error) {
+			errs = append(errs, err)
+		}},
+	}
+	ctx := context.Background()
+
+	assert.NoError(t, n.Send(ctx, types.Entry{Message: "message"}, types.FormatMessageArgs{}))
+	assert.NoError(t, n.Send(ctx, types.Entry{Message: "message"}, types.FormatMessageArgs{}))
+
+	// The reply is delivered, so the failed update is only
+	// reported.
+	assert.Len(t, f.posts, 2)
+	assert.Equal(t, []string{"ts-1"}, f.posts[1]["thread_ts"])
+	assert.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "update error")
+}
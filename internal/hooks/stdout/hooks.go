@@ -26,6 +26,10 @@ type Hook struct {
 	Writer io.Writer
 	// The slice of log levels the writer can too.
 	LogLevels []logrus.Level
+	// Enabled determines if an entry with the level should
+	// be written, if it's nil all entries within LogLevels
+	// are written.
+	Enabled func(level logrus.Level) bool
 }
 
 // Fire will be called when some logging function is
@@ -35,6 +39,10 @@ type Hook struct {
 func (hook *Hook) Fire(entry *logrus.Entry) error {
 	const op = "Logger.Hook.Fire"
 
+	if hook.Enabled != nil && !hook.Enabled(entry.Level) {
+		return nil
+	}
+
 	line, err := entry.String()
 	if err != nil {
 		return &errors.Error{Code: errors.INTERNAL, Message: "Error obtaining the entry string", Operation: op, Err: err}
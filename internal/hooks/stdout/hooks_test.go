@@ -101,6 +101,23 @@ func TestHook_Fire(t *testing.T) {
 	}
 }
 
+func TestHook_Enabled(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := SetupHooks(buf)
+	h.Enabled = func(level logrus.Level) bool {
+		return level <= logrus.WarnLevel
+	}
+	logger := &logrus.Logger{Formatter: &mockFormat{}}
+
+	err := h.Fire(&logrus.Entry{Logger: logger, Level: logrus.InfoLevel})
+	assert.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	err = h.Fire(&logrus.Entry{Logger: logger, Level: logrus.ErrorLevel})
+	assert.NoError(t, err)
+	assert.Equal(t, "test", buf.String())
+}
+
 func TestHook_Levels(t *testing.T) {
 	h := SetupHooks(nil)
 	want := []logrus.Level{
@@ -0,0 +1,197 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teams
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ainsleyclark/logger/internal/hooks"
+	"github.com/ainsleyclark/logger/internal/httputil"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// Notifier represents the Microsoft Teams notifier for
+	// log entries.
+	Notifier struct {
+		client  *http.Client
+		options Options
+	}
+	// Options defines the configuration needed to send logs
+	// to a Teams incoming webhook.
+	Options struct {
+		URL     string
+		Timeout time.Duration
+	}
+	// message is the body sent to the incoming webhook.
+	message struct {
+		Type        string       `json:"type"`
+		Attachments []attachment `json:"attachments"`
+	}
+	// attachment wraps the Adaptive Card.
+	attachment struct {
+		ContentType string `json:"contentType"`
+		Content     card   `json:"content"`
+	}
+	// card is an Adaptive Card, see
+	// https://adaptivecards.io/explorer/AdaptiveCard.html
+	card struct {
+		Schema  string    `json:"$schema"`
+		Type    string    `json:"type"`
+		Version string    `json:"version"`
+		Body    []element `json:"body"`
+	}
+	// element is a TextBlock or FactSet within the card.
+	element struct {
+		Type   string `json:"type"`
+		Text   string `json:"text,omitempty"`
+		Weight string `json:"weight,omitempty"`
+		Size   string `json:"size,omitempty"`
+		Color  string `json:"color,omitempty"`
+		Wrap   bool   `json:"wrap,omitempty"`
+		Facts  []fact `json:"facts,omitempty"`
+	}
+	// fact is a single title and value pair of a FactSet.
+	fact struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+	}
+)
+
+// New creates a new Teams notifier.
+func New(opts Options) *Notifier {
+	if opts.Timeout == 0 {
+		opts.Timeout = hooks.DefaultTimeout
+	}
+	return &Notifier{
+		client:  &http.Client{Timeout: opts.Timeout},
+		options: opts,
+	}
+}
+
+// Send renders the entry as an Adaptive Card and posts it
+// to the incoming webhook.
+func (n *Notifier) Send(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+	body, err := json.Marshal(message{
+		Type: "message",
+		Attachments: []attachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content:     newCard(entry, args),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return httputil.PostJSON(ctx, n.client, n.options.URL, body, nil, "teams")
+}
+
+// newCard creates the Adaptive Card for the entry. The
+// formatted message is only added if a formatter has
+// been set, as the facts already describe the entry.
+func newCard(entry types.Entry, args types.FormatMessageArgs) card {
+	title := args.Service
+	if args.Version != "" {
+		title += " v" + strings.TrimPrefix(args.Version, "v")
+	}
+
+	body := []element{
+		{
+			Type:   "TextBlock",
+			Text:   title,
+			Weight: "Bolder",
+			Size:   "Medium",
+			Color:  colour(entry.Level),
+			Wrap:   true,
+		},
+	}
+
+	if args.Formatter != nil {
+		body = append(body, element{
+			Type: "TextBlock",
+			Text: args.Message(entry),
+			Wrap: true,
+		})
+	}
+
+	facts := []fact{
+		{Title: "Level", Value: strings.ToUpper(entry.Level.String())},
+		{Title: "Service", Value: args.Service},
+	}
+	if args.Version != "" {
+		facts = append(facts, fact{Title: "Version", Value: args.Version})
+	}
+	facts = append(facts, fact{Title: "Time", Value: entry.Time.Format(time.RFC3339)})
+	if entry.Message != "" {
+		facts = append(facts, fact{Title: "Message", Value: entry.Message})
+	}
+	if e := entry.Error(); e != nil {
+		facts = append(facts,
+			fact{Title: "Code", Value: e.Code},
+			fact{Title: "Operation", Value: e.Operation},
+			fact{Title: "Fileline", Value: e.FileLine()},
+		)
+		if e.Message != "" {
+			facts = append(facts, fact{Title: "Error Message", Value: e.Message})
+		}
+		if e.Err != nil {
+			facts = append(facts, fact{Title: "Error", Value: e.Err.Error()})
+		}
+	}
+	body = append(body, element{Type: "FactSet", Facts: facts})
+
+	if fields := entry.Fields(); len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		ff := make([]fact, len(keys))
+		for i, k := range keys {
+			ff[i] = fact{Title: k, Value: fmt.Sprintf("%v", fields[k])}
+		}
+		body = append(body,
+			element{Type: "TextBlock", Text: "Fields", Weight: "Bolder"},
+			element{Type: "FactSet", Facts: ff},
+		)
+	}
+
+	return card{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body:    body,
+	}
+}
+
+// colour returns the Adaptive Card text colour for the
+// level.
+func colour(level logrus.Level) string {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel:
+		return "Attention"
+	case logrus.WarnLevel:
+		return "Warning"
+	default:
+		return "Default"
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teams
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/hooks"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	got := New(Options{URL: "http://localhost"})
+	assert.Equal(t, hooks.DefaultTimeout, got.client.Timeout)
+	assert.Equal(t, "http://localhost", got.options.URL)
+}
+
+func TestNotifier_Send(t *testing.T) {
+	// Built the same way as Logger.WithFields.
+	entry := types.Entry(*logrus.WithFields(logrus.Fields{
+		types.FieldKey: types.Fields{"b": 2, "a": 1},
+	}).WithError(errors.NewInternal(errors.New("error"), "message", "op")))
+	entry.Level = logrus.ErrorLevel
+	entry.Message = "message"
+
+	tt := map[string]struct {
+		status int
+		want   any
+	}{
+		"Success": {
+			http.StatusOK,
+			nil,
+		},
+		"Bad Status": {
+			http.StatusBadRequest,
+			"status code 400",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			var got message
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			n := New(Options{URL: server.URL})
+			err := n.Send(context.Background(), entry, types.FormatMessageArgs{Service: "api"})
+			if err != nil {
+				assert.Contains(t, err.Error(), test.want)
+				return
+			}
+			assert.Equal(t, test.want, err)
+			assert.Equal(t, "message", got.Type)
+			assert.Len(t, got.Attachments, 1)
+			assert.Equal(t, "application/vnd.microsoft.card.adaptive", got.Attachments[0].ContentType)
+			assert.Equal(t, "AdaptiveCard", got.Attachments[0].Content.Type)
+		})
+	}
+}
+
+func TestNotifier_SendCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n := New(Options{URL: "http://localhost"})
+	err := n.Send(ctx, types.Entry{}, types.FormatMessageArgs{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNewCard(t *testing.T) {
+	entry := types.Entry(*logrus.WithFields(logrus.Fields{
+		types.FieldKey: types.Fields{"b": 2, "a": 1},
+	}).WithError(&errors.Error{Code: errors.INTERNAL, Message: "error message", Operation: "op", Err: errors.New("error")}))
+	entry.Level = logrus.ErrorLevel
+	entry.Time = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry.Message = "message"
+
+	t.Run("Facts", func(t *testing.T) {
+		got := newCard(entry, types.FormatMessageArgs{Service: "api", Version: "v0.0.1"})
+		assert.Equal(t, "1.4", got.Version)
+		assert.Len(t, got.Body, 4)
+		assert.Equal(t, "api v0.0.1", got.Body[0].Text)
+		assert.Equal(t, "Attention", got.Body[0].Color)
+
+		facts := map[string]string{}
+		for _, f := range got.Body[1].Facts {
+			facts[f.Title] = f.Value
+		}
+		assert.Equal(t, "ERROR", facts["Level"])
+		assert.Equal(t, "api", facts["Service"])
+		assert.Equal(t, "v0.0.1", facts["Version"])
+		assert.Equal(t, "2022-01-01T00:00:00Z", facts["Time"])
+		assert.Equal(t, "message", facts["Message"])
+		assert.Equal(t, errors.INTERNAL, facts["Code"])
+		assert.Equal(t, "op", facts["Operation"])
+		assert.Equal(t, "error message", facts["Error Message"])
+		assert.Equal(t, "error", facts["Error"])
+		assert.Contains(t, facts, "Fileline")
+
+		assert.Equal(t, []fact{{Title: "a", Value: "1"}, {Title: "b", Value: "2"}}, got.Body[3].Facts)
+	})
+
+	t.Run("Formatter", func(t *testing.T) {
+		got := newCard(types.Entry{Level: logrus.WarnLevel}, types.FormatMessageArgs{
+			Formatter: func(entry types.Entry, args types.FormatMessageArgs) string {
+				return "formatted"
+			},
+		})
+		assert.Len(t, got.Body, 3)
+		assert.Equal(t, "Warning", got.Body[0].Color)
+		assert.Equal(t, "formatted", got.Body[1].Text)
+	})
+}
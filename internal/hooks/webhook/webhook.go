@@ -0,0 +1,196 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/hooks"
+	"github.com/ainsleyclark/logger/internal/httputil"
+	"github.com/ainsleyclark/logger/internal/retry"
+	"github.com/ainsleyclark/logger/types"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+const (
+	// DefaultSignatureHeader is the default header the HMAC
+	// signature is sent with.
+	DefaultSignatureHeader = "X-Logger-Signature"
+)
+
+type (
+	// Notifier represents the webhook notifier for log
+	// entries.
+	Notifier struct {
+		client   *http.Client
+		template *template.Template
+		options  Options
+	}
+	// Options defines the configuration needed to send logs
+	// to an HTTP endpoint.
+	Options struct {
+		// URL is the endpoint entries are POSTed to.
+		URL string
+		// Headers are added to every request.
+		Headers map[string]string
+		// Template is a text/template used to render the JSON
+		// body, the Payload is passed as the data. If it's
+		// empty the Payload is encoded as JSON.
+		Template string
+		// Secret signs the body with HMAC-SHA256, the hex
+		// encoded signature is sent within the SignatureHeader.
+		Secret string
+		// SignatureHeader defaults to DefaultSignatureHeader.
+		SignatureHeader string
+		// Timeout defaults to hooks.DefaultTimeout.
+		Timeout time.Duration
+	}
+	// Payload is the data used to render the request body.
+	Payload struct {
+		Service string         `json:"service"`
+		Version string         `json:"version"`
+		Prefix  string         `json:"prefix"`
+		Level   string         `json:"level"`
+		Time    time.Time      `json:"time"`
+		Message string         `json:"message"`
+		Text    string         `json:"text"`
+		Error   *Error         `json:"error,omitempty"`
+		Fields  map[string]any `json:"fields,omitempty"`
+	}
+	// Error is the error attached to the entry, if any.
+	Error struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		Operation string `json:"operation"`
+		Err       string `json:"error"`
+		FileLine  string `json:"fileline"`
+	}
+)
+
+// New creates a new webhook notifier, an error is returned
+// if the URL is empty or the template is invalid.
+func New(opts Options) (*Notifier, error) {
+	if opts.URL == "" {
+		return nil, errors.New("webhook url cannot be empty")
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = hooks.DefaultTimeout
+	}
+	if opts.SignatureHeader == "" {
+		opts.SignatureHeader = DefaultSignatureHeader
+	}
+	n := &Notifier{
+		client:  &http.Client{Timeout: opts.Timeout},
+		options: opts,
+	}
+	if opts.Template != "" {
+		tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": toJSON}).Parse(opts.Template)
+		if err != nil {
+			return nil, err
+		}
+		n.template = tmpl
+	}
+	return n, nil
+}
+
+// Send renders the entry and posts it to the URL.
+func (n *Notifier) Send(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+	body, err := n.body(NewPayload(entry, args))
+	if err != nil {
+		return err
+	}
+
+	header := make(map[string]string, len(n.options.Headers)+1)
+	for k, v := range n.options.Headers {
+		header[k] = v
+	}
+	if n.options.Secret != "" {
+		header[n.options.SignatureHeader] = Sign(n.options.Secret, body)
+	}
+
+	return httputil.PostJSON(ctx, n.client, n.options.URL, body, header, "webhook")
+}
+
+// body renders the payload with the template, or encodes it
+// as JSON if there is none. Errors are marked as permanent
+// as the same entry will always fail to render.
+func (n *Notifier) body(p Payload) ([]byte, error) {
+	if n.template == nil {
+		b, err := json.Marshal(p)
+		if err != nil {
+			return nil, retry.Permanent(err)
+		}
+		return b, nil
+	}
+	buf := &bytes.Buffer{}
+	err := n.template.Execute(buf, p)
+	if err != nil {
+		return nil, retry.Permanent(err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, retry.Permanent(errors.New("webhook template did not render valid JSON"))
+	}
+	return buf.Bytes(), nil
+}
+
+// NewPayload creates the Payload for the entry.
+func NewPayload(entry types.Entry, args types.FormatMessageArgs) Payload {
+	p := Payload{
+		Service: args.Service,
+		Version: args.Version,
+		Prefix:  args.Prefix,
+		Level:   entry.Level.String(),
+		Time:    entry.Time,
+		Message: entry.Message,
+		Text:    args.Message(entry),
+		Fields:  entry.Fields(),
+	}
+	if e := entry.Error(); e != nil {
+		p.Error = &Error{
+			Code:      e.Code,
+			Message:   e.Message,
+			Operation: e.Operation,
+			FileLine:  e.FileLine(),
+		}
+		if e.Err != nil {
+			p.Error.Err = e.Err.Error()
+		}
+	}
+	return p
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of
+// the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// toJSON encodes the value as JSON for use within
+// templates, so strings are quoted and escaped.
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,195 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/hooks"
+	"github.com/ainsleyclark/logger/internal/retry"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tt := map[string]struct {
+		input Options
+		want  any
+	}{
+		"Success": {
+			Options{URL: "http://localhost", Template: `{"text": {{ json .Text }}}`},
+			nil,
+		},
+		"No URL": {
+			Options{},
+			"url cannot be empty",
+		},
+		"Bad Template": {
+			Options{URL: "http://localhost", Template: "{{ .Text "},
+			"unclosed action",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := New(test.input)
+			if err != nil {
+				assert.Contains(t, err.Error(), test.want)
+				return
+			}
+			assert.Equal(t, hooks.DefaultTimeout, got.client.Timeout)
+			assert.Equal(t, DefaultSignatureHeader, got.options.SignatureHeader)
+		})
+	}
+}
+
+func TestNotifier_Send(t *testing.T) {
+	// Built the same way as Logger.WithFields.
+	entry := types.Entry(*logrus.WithFields(logrus.Fields{
+		types.FieldKey: types.Fields{"user": "alice"},
+	}).WithError(errors.NewInternal(errors.New("error"), "message", "op")))
+	entry.Level = logrus.ErrorLevel
+	entry.Message = "message"
+	args := types.FormatMessageArgs{
+		Service: "service",
+		Formatter: func(entry types.Entry, args types.FormatMessageArgs) string {
+			return "text"
+		},
+	}
+
+	tt := map[string]struct {
+		options Options
+		status  int
+		delay   time.Duration
+		want    any
+		check   func(t *testing.T, r *http.Request, body []byte)
+	}{
+		"Default Body": {
+			Options{Headers: map[string]string{"Authorization": "Bearer token"}},
+			http.StatusOK,
+			0,
+			nil,
+			func(t *testing.T, r *http.Request, body []byte) {
+				var p Payload
+				assert.NoError(t, json.Unmarshal(body, &p))
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.Equal(t, "", r.Header.Get(DefaultSignatureHeader))
+				assert.Equal(t, "service", p.Service)
+				assert.Equal(t, "error", p.Level)
+				assert.Equal(t, "text", p.Text)
+				assert.Equal(t, errors.INTERNAL, p.Error.Code)
+				assert.Equal(t, "op", p.Error.Operation)
+				assert.Equal(t, map[string]any{"user": "alice"}, p.Fields)
+			},
+		},
+		"Template": {
+			Options{Template: `{"summary": {{ json .Text }}, "code": {{ json .Error.Code }}}`},
+			http.StatusAccepted,
+			0,
+			nil,
+			func(t *testing.T, r *http.Request, body []byte) {
+				assert.JSONEq(t, `{"summary": "text", "code": "internal"}`, string(body))
+			},
+		},
+		"Signed": {
+			Options{Secret: "secret", SignatureHeader: "X-Signature"},
+			http.StatusOK,
+			0,
+			nil,
+			func(t *testing.T, r *http.Request, body []byte) {
+				assert.Equal(t, Sign("secret", body), r.Header.Get("X-Signature"))
+			},
+		},
+		"Invalid JSON": {
+			Options{Template: `{{ .Text }}`},
+			http.StatusOK,
+			0,
+			"valid JSON",
+			nil,
+		},
+		"Bad Status": {
+			Options{},
+			http.StatusInternalServerError,
+			0,
+			"status code 500",
+			nil,
+		},
+		"Timeout": {
+			Options{Timeout: time.Millisecond * 10},
+			http.StatusOK,
+			time.Millisecond * 100,
+			"Client.Timeout",
+			nil,
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				if test.check != nil {
+					test.check(t, r, body)
+				}
+				time.Sleep(test.delay)
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			test.options.URL = server.URL
+			n, err := New(test.options)
+			assert.NoError(t, err)
+
+			err = n.Send(context.Background(), entry, args)
+			if err != nil {
+				assert.Contains(t, err.Error(), test.want)
+				return
+			}
+			assert.Equal(t, test.want, err)
+		})
+	}
+}
+
+func TestNotifier_SendTemplateError(t *testing.T) {
+	tt := map[string]string{
+		"Execute":      `{"a": {{ .Missing }}}`,
+		"Invalid JSON": `{{ .Text }}`,
+	}
+
+	for name, tmpl := range tt {
+		t.Run(name, func(t *testing.T) {
+			n, err := New(Options{URL: "http://localhost", Template: tmpl})
+			assert.NoError(t, err)
+
+			// Rendering errors are permanent so they shouldn't
+			// be retried.
+			attempts := 0
+			err = retry.Default().Do(context.Background(), func(ctx context.Context) error {
+				attempts++
+				return n.Send(ctx, types.Entry{}, types.FormatMessageArgs{})
+			})
+			assert.Error(t, err)
+			assert.Equal(t, 1, attempts)
+		})
+	}
+}
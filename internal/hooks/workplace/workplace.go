@@ -14,67 +14,48 @@
 package workplace
 
 import (
-	"github.com/ainsleyclark/logger/internal/hooks"
+	"context"
 	"github.com/ainsleyclark/logger/types"
 	"github.com/ainsleyclark/workplace"
-	"github.com/sirupsen/logrus"
-	"log"
 )
 
-// NewHook creates a new Workplace hook.
+// New creates a new Workplace notifier.
 // Returns an error if the client could not be created.
-func NewHook(opts Options) (*Hook, error) {
+func New(opts Options) (*Notifier, error) {
 	wp, err := workplace.New(workplace.Config{Token: opts.Token})
 	if err != nil {
 		return nil, err
 	}
-	return &Hook{
-		wp:        wp,
-		options:   opts,
-		LogLevels: logrus.AllLevels,
+	return &Notifier{
+		wp:      wp,
+		options: opts,
 	}, nil
 }
 
 type (
-	// Hook represents the workplace hook notifier
-	// for log entries.
-	Hook struct {
-		wp        workplace.Notifier
-		options   Options
-		LogLevels []logrus.Level
+	// Notifier represents the Workplace notifier for log
+	// entries.
+	Notifier struct {
+		wp      workplace.Notifier
+		options Options
 	}
-	// Options defines the configuration needed to fire logs
+	// Options defines the configuration needed to send logs
 	// via the Workplace API.
 	Options struct {
-		Token         string
-		Thread        string
-		Args          types.FormatMessageArgs
-		FormatMessage types.FormatMessageFunc
+		Token  string
+		Thread string
 	}
 )
 
-// Fire will be called when some logging function is
-// called with current hook. It will format log
-// entry to string and write it to
-// appropriate writer
-func (hook *Hook) Fire(entry *logrus.Entry) error {
-	go hook.process(types.Entry(*entry)) // This is already check for nil pointer.
-	return nil
-}
-
-func (hook *Hook) process(entry types.Entry) {
+// Send formats the entry and posts it to the Workplace
+// thread.
+func (n *Notifier) Send(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	// Use the Workplace client to send a message via the bot.
-	err := hook.wp.Notify(workplace.Transmission{
-		Thread:  hook.options.Thread,
-		Message: hooks.GetMessage(entry, hook.options.Args, hook.options.FormatMessage),
+	return n.wp.Notify(workplace.Transmission{
+		Thread:  n.options.Thread,
+		Message: args.Message(entry),
 	})
-	if err != nil {
-		log.Println(err.Error()) // We can't use the standard logger as it may cause a loop.
-	}
-}
-
-// Levels Define on which log levels this hook would
-// trigger.
-func (hook *Hook) Levels() []logrus.Level {
-	return hook.LogLevels
 }
@@ -14,19 +14,17 @@
 package workplace
 
 import (
-	"bytes"
+	"context"
 	"github.com/ainsleyclark/errors"
 	mocks "github.com/ainsleyclark/logger/gen/mocks/test"
 	"github.com/ainsleyclark/logger/types"
-	"github.com/sirupsen/logrus"
+	"github.com/ainsleyclark/workplace"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"log"
-	"os"
 	"testing"
 )
 
-func TestNewHook(t *testing.T) {
+func TestNew(t *testing.T) {
 	tt := map[string]struct {
 		input string
 		want  any
@@ -43,7 +41,7 @@ func TestNewHook(t *testing.T) {
 
 	for name, test := range tt {
 		t.Run(name, func(t *testing.T) {
-			_, err := NewHook(Options{Token: test.input})
+			_, err := New(Options{Token: test.input})
 			if err != nil {
 				assert.Contains(t, err.Error(), test.want)
 				return
@@ -52,16 +50,7 @@ func TestNewHook(t *testing.T) {
 	}
 }
 
-func TestHook_Fire(t *testing.T) {
-	m := &mocks.Notifier{}
-	m.On("Notify", mock.Anything, mock.Anything).
-		Return(nil)
-	h := Hook{wp: m}
-	got := h.Fire(&logrus.Entry{})
-	assert.Nil(t, got)
-}
-
-func TestHook_Process(t *testing.T) {
+func TestNotifier_Send(t *testing.T) {
 	entry := types.Entry{
 		Message: "message",
 		Data: map[string]any{
@@ -72,47 +61,56 @@ func TestHook_Process(t *testing.T) {
 
 	tt := map[string]struct {
 		mock func(m *mocks.Notifier)
+		ctx  func() context.Context
 		want any
 	}{
 		"Success": {
 			func(m *mocks.Notifier) {
-				m.On("Notify", mock.Anything, mock.Anything).
+				m.On("Notify", workplace.Transmission{Thread: "thread", Message: "formatted"}).
 					Return(nil)
 			},
-			"",
+			context.Background,
+			nil,
 		},
 		"Error": {
 			func(m *mocks.Notifier) {
-				m.On("Notify", mock.Anything, mock.Anything).
+				m.On("Notify", mock.Anything).
 					Return(errors.New("error"))
 			},
+			context.Background,
 			"error",
 		},
+		"Context Cancelled": {
+			nil,
+			func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			context.Canceled.Error(),
+		},
 	}
 
 	for name, test := range tt {
 		t.Run(name, func(t *testing.T) {
-			var buf bytes.Buffer
-			log.SetOutput(&buf)
-			defer func() {
-				log.SetOutput(os.Stderr)
-			}()
 			m := &mocks.Notifier{}
 			if test.mock != nil {
 				test.mock(m)
 			}
-			h := Hook{
-				wp:        m,
-				LogLevels: logrus.AllLevels,
+			n := Notifier{
+				wp:      m,
+				options: Options{Thread: "thread"},
 			}
-			h.process(entry)
-			assert.Contains(t, buf.String(), test.want)
+			err := n.Send(test.ctx(), entry, types.FormatMessageArgs{
+				Formatter: func(entry types.Entry, args types.FormatMessageArgs) string {
+					return "formatted"
+				},
+			})
+			if err != nil {
+				assert.Contains(t, err.Error(), test.want)
+				return
+			}
+			assert.Equal(t, test.want, err)
 		})
 	}
 }
-
-func TestHook_Levels(t *testing.T) {
-	h := Hook{LogLevels: logrus.AllLevels}
-	want := logrus.AllLevels
-	assert.Equal(t, want, h.Levels())
-}
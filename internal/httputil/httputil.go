@@ -0,0 +1,65 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/ainsleyclark/logger/internal/retry"
+	"io"
+	"net/http"
+)
+
+// PostJSON posts the JSON body to the URL with the headers
+// passed. An error is returned for any non 2xx response,
+// client errors other than 408 and 429 are marked as
+// permanent and the Retry-After header is honoured.
+// The name is used within error messages.
+func PostJSON(ctx context.Context, client *http.Client, url string, body []byte, header map[string]string, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return StatusError(resp, name)
+}
+
+// StatusError returns an error if the response doesn't
+// have a 2xx status code.
+func StatusError(resp *http.Response, name string) error {
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+	err := fmt.Errorf("%s returned status code %d", name, resp.StatusCode)
+	if d, ok := retry.ParseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		return retry.After(err, d)
+	}
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode < http.StatusInternalServerError &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return retry.Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputil
+
+import (
+	"context"
+	"errors"
+	"github.com/ainsleyclark/logger/internal/retry"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPostJSON(t *testing.T) {
+	tt := map[string]struct {
+		status     int
+		retryAfter string
+		want       any
+		permanent  bool
+		after      time.Duration
+	}{
+		"Success": {
+			http.StatusOK,
+			"",
+			nil,
+			false,
+			0,
+		},
+		"Server Error": {
+			http.StatusInternalServerError,
+			"",
+			"name returned status code 500",
+			false,
+			0,
+		},
+		"Bad Request": {
+			http.StatusBadRequest,
+			"",
+			"name returned status code 400",
+			true,
+			0,
+		},
+		"Too Many Requests": {
+			http.StatusTooManyRequests,
+			"2",
+			"name returned status code 429",
+			false,
+			time.Second * 2,
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, `{"a":1}`, string(body))
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.Equal(t, "value", r.Header.Get("X-Key"))
+				if test.retryAfter != "" {
+					w.Header().Set("Retry-After", test.retryAfter)
+				}
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			err := PostJSON(context.Background(), server.Client(), server.URL, []byte(`{"a":1}`), map[string]string{"X-Key": "value"}, "name")
+			if err == nil {
+				assert.Equal(t, test.want, err)
+				return
+			}
+			assert.Contains(t, err.Error(), test.want)
+
+			after, ok := retry.RetryAfter(err)
+			assert.Equal(t, test.after != 0, ok)
+			assert.Equal(t, test.after, after)
+			if ok {
+				return
+			}
+
+			// A permanent error is returned without retrying.
+			attempts := 0
+			_ = retry.Policy{MaxAttempts: 2}.Do(context.Background(), func(ctx context.Context) error {
+				attempts++
+				return err
+			})
+			assert.Equal(t, test.permanent, attempts == 1)
+		})
+	}
+}
+
+func TestPostJSON_Error(t *testing.T) {
+	err := PostJSON(context.Background(), http.DefaultClient, "://invalid", nil, nil, "name")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, context.Canceled))
+}
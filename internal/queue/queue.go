@@ -0,0 +1,233 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Policy defines what happens when an item is pushed to
+// a Queue that is full.
+type Policy int
+
+const (
+	// Block waits until there is space in the queue.
+	Block Policy = iota
+	// DropNewest discards the item being pushed.
+	DropNewest
+	// DropOldest discards the oldest item in the queue to
+	// make space for the item being pushed.
+	DropOldest
+)
+
+type (
+	// Queue is a bounded queue that processes items with a
+	// fixed number of workers.
+	Queue[T any] struct {
+		items   chan T
+		handler func(T)
+		policy  Policy
+		dropped uint64
+		workers sync.WaitGroup
+		mtx     sync.RWMutex
+		closed  bool
+		pending pending
+	}
+	// Options defines the configuration for creating a
+	// new Queue.
+	Options struct {
+		// Size is the maximum amount of items that can be
+		// waiting to be processed.
+		Size int
+		// Workers is the amount of goroutines processing
+		// items concurrently.
+		Workers int
+		// Policy defines what happens when the queue is full.
+		Policy Policy
+	}
+)
+
+// New creates a new Queue and starts the workers which
+// call the handler for every item pushed.
+func New[T any](opts Options, handler func(T)) *Queue[T] {
+	if opts.Size < 1 {
+		opts.Size = 1
+	}
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	q := &Queue[T]{
+		items:   make(chan T, opts.Size),
+		handler: handler,
+		policy:  opts.Policy,
+	}
+	q.workers.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// Push adds an item to the queue, how a full queue is
+// handled depends on the Policy.
+// Returns false if the item was dropped or the queue
+// has been closed.
+func (q *Queue[T]) Push(item T) bool {
+	q.mtx.RLock()
+	defer q.mtx.RUnlock()
+
+	if q.closed {
+		return false
+	}
+
+	q.pending.add()
+
+	switch q.policy {
+	case DropNewest:
+		select {
+		case q.items <- item:
+			return true
+		default:
+			q.drop()
+			return false
+		}
+	case DropOldest:
+		for {
+			select {
+			case q.items <- item:
+				return true
+			default:
+			}
+			select {
+			case <-q.items:
+				q.drop()
+			default:
+			}
+		}
+	default:
+		q.items <- item
+		return true
+	}
+}
+
+// Dropped returns the amount of items that have been
+// discarded because the queue was full.
+func (q *Queue[T]) Dropped() uint64 {
+	return atomic.LoadUint64(&q.dropped)
+}
+
+// Len returns the amount of items waiting to be
+// processed.
+func (q *Queue[T]) Len() int {
+	return len(q.items)
+}
+
+// Wait blocks until every item pushed has been processed
+// or the context is done, in which case the context's
+// error is returned.
+func (q *Queue[T]) Wait(ctx context.Context) error {
+	return q.pending.wait(ctx)
+}
+
+// Close stops the queue from accepting new items and
+// waits for the remaining items to be processed.
+// Returns the context's error if it is done before the
+// queue has drained.
+func (q *Queue[T]) Close(ctx context.Context) error {
+	q.mtx.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.items)
+	}
+	q.mtx.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work processes items until the queue is closed.
+func (q *Queue[T]) work() {
+	defer q.workers.Done()
+	for item := range q.items {
+		q.handler(item)
+		q.pending.done()
+	}
+}
+
+// drop records a discarded item.
+func (q *Queue[T]) drop() {
+	atomic.AddUint64(&q.dropped, 1)
+	q.pending.done()
+}
+
+// pending counts the items that have been pushed but
+// not yet processed.
+type pending struct {
+	mtx     sync.Mutex
+	count   int
+	drained chan struct{}
+}
+
+// add increments the pending count.
+func (p *pending) add() {
+	p.mtx.Lock()
+	p.count++
+	p.mtx.Unlock()
+}
+
+// done decrements the pending count and releases any
+// waiters once there are none left.
+func (p *pending) done() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.count--
+	if p.count == 0 && p.drained != nil {
+		close(p.drained)
+		p.drained = nil
+	}
+}
+
+// wait blocks until the pending count reaches zero or
+// the context is done.
+func (p *pending) wait(ctx context.Context) error {
+	p.mtx.Lock()
+	if p.count == 0 {
+		p.mtx.Unlock()
+		return nil
+	}
+	if p.drained == nil {
+		p.drained = make(chan struct{})
+	}
+	drained := p.drained
+	p.mtx.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
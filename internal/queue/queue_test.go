@@ -0,0 +1,139 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	q := New(Options{}, func(i int) {})
+	assert.Equal(t, 1, cap(q.items))
+	assert.Equal(t, Block, q.policy)
+	assert.NoError(t, q.Close(context.Background()))
+}
+
+func TestQueue_Push(t *testing.T) {
+	tt := map[string]struct {
+		policy  Policy
+		want    []int
+		dropped uint64
+	}{
+		"Block": {
+			Block,
+			[]int{0, 1, 2, 3},
+			0,
+		},
+		"Drop Newest": {
+			DropNewest,
+			[]int{0, 1, 2},
+			1,
+		},
+		"Drop Oldest": {
+			DropOldest,
+			[]int{0, 2, 3},
+			1,
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			var (
+				mtx     sync.Mutex
+				got     []int
+				started = make(chan struct{})
+				release = make(chan struct{})
+			)
+			q := New(Options{Size: 2, Workers: 1, Policy: test.policy}, func(i int) {
+				if i == 0 {
+					close(started)
+					<-release
+				}
+				mtx.Lock()
+				got = append(got, i)
+				mtx.Unlock()
+			})
+
+			q.Push(0)
+			<-started
+			q.Push(1)
+			q.Push(2)
+
+			if test.policy == Block {
+				go func() {
+					time.Sleep(time.Millisecond * 10)
+					close(release)
+				}()
+				assert.True(t, q.Push(3))
+			} else {
+				q.Push(3)
+				close(release)
+			}
+
+			assert.NoError(t, q.Wait(context.Background()))
+			assert.NoError(t, q.Close(context.Background()))
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.dropped, q.Dropped())
+		})
+	}
+}
+
+func TestQueue_Wait(t *testing.T) {
+	release := make(chan struct{})
+	q := New(Options{Size: 1, Workers: 1}, func(i int) {
+		<-release
+	})
+	q.Push(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	assert.ErrorIs(t, q.Wait(ctx), context.DeadlineExceeded)
+	assert.LessOrEqual(t, q.Len(), 1)
+
+	close(release)
+	assert.NoError(t, q.Wait(context.Background()))
+	assert.NoError(t, q.Close(context.Background()))
+}
+
+func TestQueue_Close(t *testing.T) {
+	var processed int32
+	q := New(Options{Size: 10, Workers: 2}, func(i int) {
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&processed, 1)
+	})
+	for i := 0; i < 10; i++ {
+		q.Push(i)
+	}
+	assert.NoError(t, q.Close(context.Background()))
+	assert.Equal(t, int32(10), atomic.LoadInt32(&processed))
+	assert.False(t, q.Push(11))
+	assert.NoError(t, q.Close(context.Background()))
+}
+
+func TestQueue_CloseDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	q := New(Options{Size: 1, Workers: 1}, func(i int) {
+		<-release
+	})
+	q.Push(1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	assert.ErrorIs(t, q.Close(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,183 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"fmt"
+	"github.com/ainsleyclark/logger/types"
+	"sync"
+	"time"
+)
+
+type (
+	// Limiter limits the amount of similar entries that are
+	// sent within a window, entries are keyed on their
+	// fingerprint. Entries over the limit are suppressed
+	// and a Summary is passed to the callback once the
+	// window has ended.
+	Limiter struct {
+		limit   int
+		window  time.Duration
+		fn      func(Summary)
+		mtx     sync.Mutex
+		windows map[string]*window
+	}
+	// Summary describes the entries that were suppressed
+	// within a window.
+	Summary struct {
+		// Entry is the last entry that was suppressed.
+		Entry types.Entry
+		// Suppressed is the amount of entries suppressed.
+		Suppressed int
+		// Duration is the length of the window.
+		Duration time.Duration
+	}
+	// window is the state for a single fingerprint.
+	window struct {
+		start      time.Time
+		sent       int
+		suppressed int
+		last       types.Entry
+		timer      *time.Timer
+	}
+)
+
+// New creates a Limiter that allows limit entries with the
+// same fingerprint per interval. The callback is called with
+// a Summary when a window that has suppressed entries
+// ends.
+func New(limit int, interval time.Duration, fn func(Summary)) *Limiter {
+	if limit < 1 {
+		limit = 1
+	}
+	return &Limiter{
+		limit:   limit,
+		window:  interval,
+		fn:      fn,
+		windows: make(map[string]*window),
+	}
+}
+
+// Allow reports whether the entry should be sent. The first
+// entry with a fingerprint starts a new window, once the
+// limit has been reached the rest are suppressed until
+// the window ends.
+func (l *Limiter) Allow(entry types.Entry) bool {
+	key := entry.Fingerprint()
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	w, ok := l.windows[key]
+	if !ok {
+		w = &window{start: time.Now()}
+		w.timer = time.AfterFunc(l.window, func() {
+			l.expire(key, w)
+		})
+		l.windows[key] = w
+	}
+	if w.sent < l.limit {
+		w.sent++
+		return true
+	}
+	w.suppressed++
+	w.last = entry
+	return false
+}
+
+// Flush ends every window immediately, summaries are sent
+// for the windows that have suppressed entries.
+func (l *Limiter) Flush() {
+	l.mtx.Lock()
+	var summaries []Summary
+	for key, w := range l.windows {
+		w.timer.Stop()
+		delete(l.windows, key)
+		if w.suppressed > 0 {
+			summaries = append(summaries, w.summary())
+		}
+	}
+	l.mtx.Unlock()
+	for _, s := range summaries {
+		l.fn(s)
+	}
+}
+
+// Len returns the amount of open windows.
+func (l *Limiter) Len() int {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return len(l.windows)
+}
+
+// expire ends the window for the key, if it hasn't already
+// been replaced by a newer one.
+func (l *Limiter) expire(key string, w *window) {
+	l.mtx.Lock()
+	if l.windows[key] != w {
+		l.mtx.Unlock()
+		return
+	}
+	delete(l.windows, key)
+	suppressed := w.suppressed
+	l.mtx.Unlock()
+	if suppressed > 0 {
+		l.fn(w.summary())
+	}
+}
+
+// summary returns the Summary for the window.
+func (w *window) summary() Summary {
+	return Summary{
+		Entry:      w.last,
+		Suppressed: w.suppressed,
+		Duration:   time.Since(w.start),
+	}
+}
+
+// Message returns a readable description of the summary,
+// for example "Suppressed 342 similar errors in the last
+// 5m".
+func (s Summary) Message() string {
+	isErr := s.Entry.Error() != nil
+	var noun string
+	switch {
+	case isErr && s.Suppressed == 1:
+		noun = "error"
+	case isErr:
+		noun = "errors"
+	case s.Suppressed == 1:
+		noun = "entry"
+	default:
+		noun = "entries"
+	}
+	return fmt.Sprintf("Suppressed %d similar %s in the last %s", s.Suppressed, noun, FormatDuration(s.Duration))
+}
+
+// FormatDuration formats the duration rounded to the
+// nearest second without trailing zero units, for
+// example 5m rather than 5m0s.
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Second {
+		return "1s"
+	}
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0 && d < time.Hour:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return d.String()
+}
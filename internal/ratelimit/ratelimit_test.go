@@ -0,0 +1,160 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder records the summaries that have been sent.
+type recorder struct {
+	mtx       sync.Mutex
+	summaries []Summary
+}
+
+func (r *recorder) send(s Summary) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.summaries = append(r.summaries, s)
+}
+
+func (r *recorder) get() []Summary {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.summaries
+}
+
+func entry(msg string) types.Entry {
+	return types.Entry{
+		Level:   logrus.ErrorLevel,
+		Message: msg,
+		Data:    logrus.Fields{types.ErrorKey: errors.NewInternal(errors.New("error"), msg, "op")},
+	}
+}
+
+func TestNew(t *testing.T) {
+	l := New(0, time.Minute, func(s Summary) {})
+	assert.Equal(t, 1, l.limit)
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	r := &recorder{}
+	l := New(2, time.Hour, r.send)
+
+	var allowed int
+	for i := 0; i < 10; i++ {
+		if l.Allow(entry("first")) {
+			allowed++
+		}
+	}
+	assert.Equal(t, 2, allowed)
+
+	// Entries with a different fingerprint have their own
+	// window.
+	assert.True(t, l.Allow(entry("second")))
+	assert.Equal(t, 2, l.Len())
+	assert.Nil(t, r.get())
+
+	l.Flush()
+	got := r.get()
+	assert.Len(t, got, 1)
+	assert.Equal(t, 8, got[0].Suppressed)
+	assert.Equal(t, "first", got[0].Entry.Message)
+	assert.Equal(t, 0, l.Len())
+
+	// A new window is started after flushing.
+	assert.True(t, l.Allow(entry("first")))
+}
+
+func TestLimiter_Expire(t *testing.T) {
+	r := &recorder{}
+	l := New(1, time.Millisecond*20, r.send)
+
+	assert.True(t, l.Allow(entry("message")))
+	assert.False(t, l.Allow(entry("message")))
+	assert.False(t, l.Allow(entry("message")))
+
+	assert.Eventually(t, func() bool {
+		return len(r.get()) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 2, r.get()[0].Suppressed)
+	assert.Equal(t, 0, l.Len())
+	assert.True(t, l.Allow(entry("message")))
+}
+
+func TestLimiter_ExpireNoneSuppressed(t *testing.T) {
+	r := &recorder{}
+	l := New(1, time.Millisecond, r.send)
+
+	assert.True(t, l.Allow(entry("message")))
+	assert.Eventually(t, func() bool {
+		return l.Len() == 0
+	}, time.Second, time.Millisecond)
+	assert.Nil(t, r.get())
+}
+
+func TestSummary_Message(t *testing.T) {
+	tt := map[string]struct {
+		input Summary
+		want  string
+	}{
+		"Errors": {
+			Summary{Entry: entry("message"), Suppressed: 342, Duration: time.Minute * 5},
+			"Suppressed 342 similar errors in the last 5m",
+		},
+		"Error": {
+			Summary{Entry: entry("message"), Suppressed: 1, Duration: time.Second * 30},
+			"Suppressed 1 similar error in the last 30s",
+		},
+		"Entries": {
+			Summary{Entry: types.Entry{Message: "message"}, Suppressed: 2, Duration: time.Hour},
+			"Suppressed 2 similar entries in the last 1h",
+		},
+		"Entry": {
+			Summary{Entry: types.Entry{Message: "message"}, Suppressed: 1, Duration: time.Hour},
+			"Suppressed 1 similar entry in the last 1h",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.input.Message())
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tt := map[string]struct {
+		input time.Duration
+		want  string
+	}{
+		"Sub Second": {time.Millisecond, "1s"},
+		"Seconds":    {time.Second * 30, "30s"},
+		"Rounded":    {time.Minute*5 + time.Millisecond*20, "5m"},
+		"Minutes":    {time.Minute * 90, "1h30m0s"},
+		"Hours":      {time.Hour * 2, "2h"},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, FormatDuration(test.input))
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Policy defines how failed deliveries are retried.
+type Policy struct {
+	// MaxAttempts is the maximum amount of times the
+	// function is called, one or less disables retries.
+	MaxAttempts int
+	// BaseBackoff is the time waited before the first
+	// retry, it's doubled for every attempt after.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the time waited between attempts.
+	MaxBackoff time.Duration
+	// Jitter randomises the backoff by up to the fraction
+	// passed, for example 0.2 is ±20%.
+	Jitter float64
+}
+
+// Default returns the default retry policy, three attempts
+// with a backoff starting at half a second, up to 30
+// seconds with 20% jitter.
+func Default() Policy {
+	return Policy{
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond * 500,
+		MaxBackoff:  time.Second * 30,
+		Jitter:      0.2,
+	}
+}
+
+// Do calls fn until it succeeds, the maximum attempts have
+// been reached or the context is done. If the error
+// returned specifies how long to wait, see After, it's
+// used instead of the backoff, capped at MaxBackoff.
+// Errors marked as Permanent are not retried. The
+// last error is returned.
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if IsPermanent(err) {
+			return err
+		}
+		if attempt >= p.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		wait := p.Backoff(attempt)
+		if d, ok := RetryAfter(err); ok {
+			wait = d
+			if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+				wait = p.MaxBackoff
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff returns the time to wait after the attempt
+// passed has failed, starting at one.
+func (p Policy) Backoff(attempt int) time.Duration {
+	if p.BaseBackoff <= 0 {
+		return 0
+	}
+	d := float64(p.BaseBackoff) * math.Pow(2, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (rand.Float64()*2 - 1) //nolint
+	}
+	return time.Duration(d)
+}
+
+type (
+	// afterError is an error that specifies how long to
+	// wait before retrying.
+	afterError struct {
+		err   error
+		after time.Duration
+	}
+	// permanentError is an error that shouldn't be retried.
+	permanentError struct {
+		err error
+	}
+)
+
+// Error implements the error interface.
+func (e *afterError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *afterError) Unwrap() error {
+	return e.err
+}
+
+// Error implements the error interface.
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// After wraps the error with the time to wait before
+// retrying, such as a Retry-After header.
+func After(err error, d time.Duration) error {
+	return &afterError{err: err, after: d}
+}
+
+// RetryAfter returns the time to wait set by After.
+func RetryAfter(err error) (time.Duration, bool) {
+	var e *afterError
+	if errors.As(err, &e) {
+		return e.after, true
+	}
+	return 0, false
+}
+
+// Permanent wraps the error so that it's not retried.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether the error has been marked
+// as Permanent.
+func IsPermanent(err error) bool {
+	var perm *permanentError
+	return errors.As(err, &perm)
+}
+
+// ParseRetryAfter parses the value of a Retry-After
+// header, either in seconds or as an HTTP date.
+func ParseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
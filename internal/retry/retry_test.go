@@ -0,0 +1,197 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPolicy_Do(t *testing.T) {
+	policy := Policy{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+
+	tt := map[string]struct {
+		errs     []error
+		want     any
+		attempts int
+	}{
+		"Success": {
+			nil,
+			nil,
+			1,
+		},
+		"Success After Retry": {
+			[]error{errors.New("error")},
+			nil,
+			2,
+		},
+		"Max Attempts": {
+			[]error{errors.New("1"), errors.New("2"), errors.New("3"), errors.New("4")},
+			"3",
+			3,
+		},
+		"Permanent": {
+			[]error{Permanent(errors.New("permanent"))},
+			"permanent",
+			1,
+		},
+		"Retry After": {
+			[]error{After(errors.New("error"), time.Millisecond)},
+			nil,
+			2,
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			err := policy.Do(context.Background(), func(ctx context.Context) error {
+				attempts++
+				if attempts <= len(test.errs) {
+					return test.errs[attempts-1]
+				}
+				return nil
+			})
+			assert.Equal(t, test.attempts, attempts)
+			if err != nil {
+				assert.Contains(t, err.Error(), test.want)
+				return
+			}
+			assert.Equal(t, test.want, err)
+		})
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	err := Permanent(errors.New("error"))
+	assert.True(t, IsPermanent(err))
+	assert.True(t, IsPermanent(fmt.Errorf("wrapped: %w", err)))
+	assert.False(t, IsPermanent(errors.New("error")))
+	assert.False(t, IsPermanent(nil))
+}
+
+func TestPolicy_DoRetryAfter(t *testing.T) {
+	policy := Policy{MaxAttempts: 2, BaseBackoff: time.Hour}
+	start := time.Now()
+	attempts := 0
+	err := policy.Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return After(errors.New("error"), time.Millisecond*10)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestPolicy_DoRetryAfterMaxBackoff(t *testing.T) {
+	policy := Policy{MaxAttempts: 2, MaxBackoff: time.Millisecond * 10}
+	start := time.Now()
+	attempts := 0
+	err := policy.Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return After(errors.New("error"), time.Hour)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestPolicy_DoCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	policy := Policy{MaxAttempts: 10, BaseBackoff: time.Hour}
+	attempts := 0
+	err := policy.Do(ctx, func(ctx context.Context) error {
+		attempts++
+		return errors.New("error")
+	})
+	assert.ErrorContains(t, err, "error")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	tt := map[string]struct {
+		policy  Policy
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		"First": {
+			Policy{BaseBackoff: time.Second},
+			1,
+			time.Second,
+			time.Second,
+		},
+		"Exponential": {
+			Policy{BaseBackoff: time.Second},
+			3,
+			time.Second * 4,
+			time.Second * 4,
+		},
+		"Capped": {
+			Policy{BaseBackoff: time.Second, MaxBackoff: time.Second * 3},
+			5,
+			time.Second * 3,
+			time.Second * 3,
+		},
+		"Jitter": {
+			Policy{BaseBackoff: time.Second, Jitter: 0.5},
+			1,
+			time.Millisecond * 500,
+			time.Millisecond * 1500,
+		},
+		"No Backoff": {
+			Policy{},
+			3,
+			0,
+			0,
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				got := test.policy.Backoff(test.attempt)
+				assert.GreaterOrEqual(t, got, test.min)
+				assert.LessOrEqual(t, got, test.max)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	got, ok := ParseRetryAfter("5")
+	assert.True(t, ok)
+	assert.Equal(t, time.Second*5, got)
+
+	got, ok = ParseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Greater(t, got, time.Second*50)
+
+	_, ok = ParseRetryAfter("")
+	assert.False(t, ok)
+
+	_, ok = ParseRetryAfter("invalid")
+	assert.False(t, ok)
+}
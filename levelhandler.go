@@ -0,0 +1,188 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// levelBodyLimit is the maximum size of a request body
+// sent to the LevelHandler.
+const levelBodyLimit = 1 << 20
+
+// LevelState is the JSON body used by the LevelHandler to
+// report and change levels. Sinks contains the sinks that
+// have their own level, see SinkLevel, and Components
+// contains the level of each component, see Component.
+type LevelState struct {
+	Level      string            `json:"level"`
+	Sinks      map[string]string `json:"sinks"`
+	Components map[string]string `json:"components"`
+}
+
+// LevelHandler returns a http.Handler for the default
+// Logger, see Logger.LevelHandler.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		std.LevelHandler().ServeHTTP(w, r)
+	})
+}
+
+// LevelHandler returns a http.Handler that reports and
+// changes the levels of the Logger with JSON.
+//
+// A GET request responds with the current LevelState. A
+// PUT request changes the global level and the level of
+// any sinks or components within the body, fields that
+// are omitted are left unchanged. For example:
+//
+//	{"level": "info", "sinks": {"slack": "error"}, "components": {"db": "debug"}}
+//
+// If a level or sink is invalid, nothing is changed and
+// the error is returned with a 400 status code.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			err := l.putLevels(w, r)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		writeJSON(w, http.StatusOK, l.levelState())
+	})
+}
+
+// levelState returns the current levels of the Logger.
+func (l *Logger) levelState() LevelState {
+	state := LevelState{
+		Level:      l.GetLevel().String(),
+		Sinks:      make(map[string]string),
+		Components: make(map[string]string),
+	}
+	for sink, level := range l.SinkLevels() {
+		state.Sinks[sink] = level.String()
+	}
+	for name, level := range l.ComponentLevels() {
+		state.Components[name] = level.String()
+	}
+	return state
+}
+
+// putLevels validates the levels within the request body
+// and applies them if they are all valid.
+func (l *Logger) putLevels(w http.ResponseWriter, r *http.Request) error {
+	var state LevelState
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, levelBodyLimit))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&state)
+	if err != nil {
+		return fmt.Errorf("invalid body: %s", err.Error())
+	}
+
+	known := make(map[string]bool)
+	for _, sink := range l.Sinks() {
+		known[sink] = true
+	}
+
+	levels, err := parseSinkLevels(state.Sinks, known)
+	if err != nil {
+		return err
+	}
+
+	components, err := parseComponentLevels(state.Components)
+	if err != nil {
+		return err
+	}
+
+	if state.Level != "" {
+		level, err := parseLevel(state.Level)
+		if err != nil {
+			return fmt.Errorf("level: invalid value %q, %s", state.Level, err.Error())
+		}
+		l.SetLevel(level)
+	}
+	for sink, level := range levels {
+		l.SetSinkLevel(sink, level)
+	}
+	for name, level := range components {
+		l.SetComponentLevel(name, level)
+	}
+
+	return nil
+}
+
+// parseComponentLevels parses the levels for each
+// component, components that haven't been created yet
+// are allowed so their level can be set in advance.
+func parseComponentLevels(input map[string]string) (map[string]logrus.Level, error) {
+	levels := make(map[string]logrus.Level, len(input))
+	for name, v := range input {
+		if name == "" {
+			return nil, fmt.Errorf("components: name cannot be empty")
+		}
+		level, err := parseLevel(v)
+		if err != nil {
+			return nil, fmt.Errorf("components.%s: invalid value %q, %s", name, v, err.Error())
+		}
+		levels[name] = level
+	}
+	return levels, nil
+}
+
+// parseSinkLevels parses the levels for each sink, an error
+// is returned if the sink isn't within known.
+func parseSinkLevels(input map[string]string, known map[string]bool) (map[string]logrus.Level, error) {
+	levels := make(map[string]logrus.Level, len(input))
+	for sink, v := range input {
+		if !known[sink] {
+			return nil, fmt.Errorf("sinks.%s: unknown sink, must be one of %s", sink, strings.Join(sortedKeys(known), ", "))
+		}
+		level, err := parseLevel(v)
+		if err != nil {
+			return nil, fmt.Errorf("sinks.%s: invalid value %q, %s", sink, v, err.Error())
+		}
+		levels[sink] = level
+	}
+	return levels, nil
+}
+
+// sortedKeys returns the keys of the map in order.
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeJSON writes the value to the response as JSON with
+// the status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
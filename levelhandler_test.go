@@ -0,0 +1,177 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func (t *LoggerTestSuite) TestLogger_LevelHandler() {
+	tt := map[string]struct {
+		method string
+		body   string
+		status int
+		want   LevelState
+		error  string
+	}{
+		"Get": {
+			http.MethodGet,
+			"",
+			http.StatusOK,
+			LevelState{Level: "info", Sinks: map[string]string{SinkSlack: "error"}, Components: map[string]string{}},
+			"",
+		},
+		"Global": {
+			http.MethodPut,
+			`{"level": "debug"}`,
+			http.StatusOK,
+			LevelState{Level: "debug", Sinks: map[string]string{SinkSlack: "error"}, Components: map[string]string{}},
+			"",
+		},
+		"Sinks": {
+			http.MethodPut,
+			`{"sinks": {"stdout": "warn", "slack": "info"}}`,
+			http.StatusOK,
+			LevelState{Level: "info", Sinks: map[string]string{SinkSlack: "info", SinkStdout: "warning"}, Components: map[string]string{}},
+			"",
+		},
+		"Components": {
+			http.MethodPut,
+			`{"components": {"db": "debug"}}`,
+			http.StatusOK,
+			LevelState{Level: "info", Sinks: map[string]string{SinkSlack: "error"}, Components: map[string]string{"db": "debug"}},
+			"",
+		},
+		"Invalid Component Level": {
+			http.MethodPut,
+			`{"level": "debug", "components": {"db": "wrong"}}`,
+			http.StatusBadRequest,
+			LevelState{Level: "info", Sinks: map[string]string{SinkSlack: "error"}, Components: map[string]string{}},
+			"components.db: invalid value",
+		},
+		"Invalid Level": {
+			http.MethodPut,
+			`{"level": "wrong", "sinks": {"stdout": "warn"}}`,
+			http.StatusBadRequest,
+			LevelState{Level: "info", Sinks: map[string]string{SinkSlack: "error"}, Components: map[string]string{}},
+			"level: invalid value",
+		},
+		"Invalid Sink Level": {
+			http.MethodPut,
+			`{"level": "debug", "sinks": {"stdout": "wrong"}}`,
+			http.StatusBadRequest,
+			LevelState{Level: "info", Sinks: map[string]string{SinkSlack: "error"}, Components: map[string]string{}},
+			"sinks.stdout: invalid value",
+		},
+		"Unknown Sink": {
+			http.MethodPut,
+			`{"sinks": {"wrong": "debug"}}`,
+			http.StatusBadRequest,
+			LevelState{Level: "info", Sinks: map[string]string{SinkSlack: "error"}, Components: map[string]string{}},
+			"sinks.wrong: unknown sink, must be one of mongo, slack, stdout",
+		},
+		"Unknown Field": {
+			http.MethodPut,
+			`{"wrong": "debug"}`,
+			http.StatusBadRequest,
+			LevelState{Level: "info", Sinks: map[string]string{SinkSlack: "error"}, Components: map[string]string{}},
+			"invalid body",
+		},
+		"Method Not Allowed": {
+			http.MethodPost,
+			`{"level": "debug"}`,
+			http.StatusMethodNotAllowed,
+			LevelState{Level: "info", Sinks: map[string]string{SinkSlack: "error"}, Components: map[string]string{}},
+			"method not allowed",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			l, err := NewLogger(context.TODO(), NewOptions().
+				Service("service").
+				Level(logrus.InfoLevel).
+				WithSlackNotifier("token", "channel", nil, nil).
+				SinkLevel(SinkSlack, logrus.ErrorLevel))
+			t.NoError(err)
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, "/level", strings.NewReader(test.body))
+			l.LevelHandler().ServeHTTP(rr, req)
+
+			t.Equal(test.status, rr.Code)
+			t.Equal("application/json", rr.Header().Get("Content-Type"))
+			t.Equal(test.want, l.levelState())
+
+			if test.error != "" {
+				var body map[string]string
+				t.NoError(json.Unmarshal(rr.Body.Bytes(), &body))
+				t.Contains(body["error"], test.error)
+				return
+			}
+
+			var got LevelState
+			t.NoError(json.Unmarshal(rr.Body.Bytes(), &got))
+			t.Equal(test.want, got)
+		})
+	}
+}
+
+func (t *LoggerTestSuite) TestLogger_LevelHandler_Allow() {
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/level", nil)
+	Default().LevelHandler().ServeHTTP(rr, req)
+	t.Equal(http.StatusMethodNotAllowed, rr.Code)
+	t.Equal("GET, PUT", rr.Header().Get("Allow"))
+}
+
+func (t *LoggerTestSuite) TestLevelHandler() {
+	orig := std
+	defer SetDefault(orig)
+
+	l, err := NewLogger(context.TODO(), NewOptions().Service("service").Level(logrus.WarnLevel))
+	t.NoError(err)
+	h := LevelHandler()
+	SetDefault(l)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/level", nil))
+	t.Equal(http.StatusOK, rr.Code)
+	t.JSONEq(`{"level": "warning", "sinks": {}, "components": {}}`, rr.Body.String())
+}
+
+func (t *LoggerTestSuite) TestLogger_Sinks() {
+	custom := NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		return nil
+	})
+	l, err := NewLogger(context.TODO(), NewOptions().
+		Service("service").
+		WithNamedNotifier("custom", custom, nil, nil).
+		SinkLevel("custom", logrus.ErrorLevel).
+		WithSlackNotifier("token", "channel", nil, nil))
+	t.NoError(err)
+	t.Equal([]string{"custom", SinkMongo, SinkSlack, SinkStdout}, l.Sinks())
+
+	l.SetSinkLevel(SinkMongo, logrus.WarnLevel)
+	t.Equal(map[string]logrus.Level{
+		"custom":  logrus.ErrorLevel,
+		SinkMongo: logrus.WarnLevel,
+	}, l.SinkLevels())
+}
@@ -0,0 +1,207 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Sink names used for setting the minimum level of a sink
+// with SinkLevel. Webhooks are named SinkWebhook unless
+// a name is set with WebhookOptions.Name, and custom
+// notifiers use the name passed to WithNamedNotifier.
+const (
+	SinkStdout    = "stdout"
+	SinkMongo     = "mongo"
+	SinkSlack     = "slack"
+	SinkWorkplace = "workplace"
+	SinkTeams     = "teams"
+	SinkDiscord   = "discord"
+	SinkEmail     = "email"
+	SinkPagerDuty = "pagerduty"
+	SinkWebhook   = "webhook"
+)
+
+// SinkLevel sets the minimum level of entries sent to the
+// sink, such as SinkSlack or the name of a notifier. The
+// sink must be configured, stdout and Mongo can always
+// be set. Entries below the level set with Level are
+// never logged, so a sink level can only be more
+// restrictive. By default, sinks use the level set
+// with Level.
+func (op *Options) SinkLevel(sink string, level logrus.Level) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.setSinkLevel(sink, level, "")
+	})
+	return op
+}
+
+// setSinkLevel sets the level of the sink along with where
+// it was set, such as an environment variable, which is
+// used to name the source within validation errors. The
+// maps are copied as they may be shared with another
+// Config.
+func (c *Config) setSinkLevel(sink string, level logrus.Level, source string) {
+	levels := make(map[string]logrus.Level, len(c.sinkLevels)+1)
+	for k, v := range c.sinkLevels {
+		levels[k] = v
+	}
+	levels[sink] = level
+	c.sinkLevels = levels
+
+	sources := make(map[string]string, len(c.levelSources)+1)
+	for k, v := range c.levelSources {
+		sources[k] = v
+	}
+	sources[sink] = source
+	c.levelSources = sources
+}
+
+// sinkLevels holds the minimum level of each sink, levels
+// can be changed while entries are being logged.
+type sinkLevels struct {
+	mtx    sync.RWMutex
+	levels map[string]logrus.Level
+}
+
+// newSinkLevels creates sinkLevels from the levels passed.
+func newSinkLevels(levels map[string]logrus.Level) *sinkLevels {
+	s := &sinkLevels{levels: make(map[string]logrus.Level, len(levels))}
+	for sink, level := range levels {
+		s.levels[sink] = level
+	}
+	return s
+}
+
+// Enabled determines if an entry with the level should be
+// sent to the sink, sinks without a level are always
+// enabled.
+func (s *sinkLevels) Enabled(sink string, level logrus.Level) bool {
+	min, ok := s.Get(sink)
+	return !ok || level <= min
+}
+
+// Get returns the minimum level of the sink and whether
+// one has been set.
+func (s *sinkLevels) Get(sink string) (logrus.Level, bool) {
+	if s == nil {
+		return logrus.TraceLevel, false
+	}
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	level, ok := s.levels[sink]
+	return level, ok
+}
+
+// Set sets the minimum level of the sink.
+func (s *sinkLevels) Set(sink string, level logrus.Level) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.levels[sink] = level
+}
+
+// All returns a copy of the levels that have been set.
+func (s *sinkLevels) All() map[string]logrus.Level {
+	levels := make(map[string]logrus.Level)
+	if s == nil {
+		return levels
+	}
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	for sink, level := range s.levels {
+		levels[sink] = level
+	}
+	return levels
+}
+
+// SetSinkLevel sets the minimum level of entries sent to
+// the sink while the Logger is running, see SinkLevel.
+func (l *Logger) SetSinkLevel(sink string, level logrus.Level) {
+	l.levels.Set(sink, level)
+}
+
+// SinkLevels returns the minimum level of each sink that
+// has a level set.
+func (l *Logger) SinkLevels() map[string]logrus.Level {
+	return l.levels.All()
+}
+
+// Sinks returns the names of the sinks that levels can be
+// set for, stdout, Mongo and each notifier.
+func (l *Logger) Sinks() []string {
+	if l.config == nil {
+		return (&Config{}).sinks()
+	}
+	return l.config.sinks()
+}
+
+// sinks returns the names of the sinks that levels can be
+// set for in order, stdout and Mongo are always included
+// so they can be set before being configured.
+func (c *Config) sinks() []string {
+	sinks := append([]string{SinkStdout, SinkMongo}, c.notifierNames()...)
+	sort.Strings(sinks)
+	return sinks
+}
+
+// validateSinkLevels checks that each sink level is valid
+// and refers to a sink that has been configured.
+func (c *Config) validateSinkLevels() error {
+	if len(c.sinkLevels) == 0 {
+		return nil
+	}
+	sinks := c.sinks()
+	known := make(map[string]bool, len(sinks))
+	for _, sink := range sinks {
+		known[sink] = true
+	}
+	names := make([]string, 0, len(c.sinkLevels))
+	for sink := range c.sinkLevels {
+		names = append(names, sink)
+	}
+	sort.Strings(names)
+	for _, sink := range names {
+		var err error
+		if !known[sink] {
+			err = fmt.Errorf("level for sink %s is invalid, the sink must be one of %s", sink, strings.Join(sinks, ", "))
+		} else if c.sinkLevels[sink] > logrus.TraceLevel {
+			err = fmt.Errorf("level for sink %s is invalid", sink)
+		}
+		if err != nil && c.levelSources[sink] != "" {
+			return fmt.Errorf("%s: %s", c.levelSources[sink], err.Error())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stepLevel moves the level by n steps, a positive n is
+// more verbose. The level is kept between PanicLevel
+// and TraceLevel.
+func stepLevel(level logrus.Level, n int) logrus.Level {
+	next := int(level) + n
+	switch {
+	case next < int(logrus.PanicLevel):
+		return logrus.PanicLevel
+	case next > int(logrus.TraceLevel):
+		return logrus.TraceLevel
+	}
+	return logrus.Level(next)
+}
@@ -0,0 +1,218 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"github.com/ainsleyclark/logger/internal/hooks/stdout"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"sync"
+)
+
+func (t *LoggerTestSuite) TestOptions_SinkLevel() {
+	opts := NewOptions().
+		SinkLevel(SinkSlack, logrus.ErrorLevel).
+		SinkLevel(SinkMongo, logrus.InfoLevel)
+	c := &Config{}
+	for _, optFn := range opts.optFuncs {
+		optFn(c)
+	}
+	t.Equal(map[string]logrus.Level{
+		SinkSlack: logrus.ErrorLevel,
+		SinkMongo: logrus.InfoLevel,
+	}, c.sinkLevels)
+}
+
+func (t *LoggerTestSuite) TestConfig_ValidateSinkLevels() {
+	n := NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		return nil
+	})
+
+	tt := map[string]struct {
+		input *Options
+		want  any
+	}{
+		"Stdout": {
+			NewOptions().SinkLevel(SinkStdout, logrus.InfoLevel),
+			nil,
+		},
+		"Mongo": {
+			NewOptions().SinkLevel(SinkMongo, logrus.InfoLevel),
+			nil,
+		},
+		"Notifier": {
+			NewOptions().
+				WithWebhookNotifier(NewWebhookOptions("http://localhost").Name("incidents")).
+				WithNamedNotifier("custom", n, nil, nil).
+				SinkLevel("incidents", logrus.ErrorLevel).
+				SinkLevel("custom", logrus.WarnLevel),
+			nil,
+		},
+		"Not Configured": {
+			NewOptions().SinkLevel(SinkSlack, logrus.ErrorLevel),
+			"level for sink slack is invalid, the sink must be one of mongo, stdout",
+		},
+		"Unknown": {
+			NewOptions().
+				WithNamedNotifier("custom", n, nil, nil).
+				SinkLevel("costum", logrus.ErrorLevel),
+			"level for sink costum is invalid, the sink must be one of custom, mongo, stdout",
+		},
+		"Invalid Level": {
+			NewOptions().SinkLevel(SinkStdout, logrus.Level(100)),
+			"level for sink stdout is invalid",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			c := &Config{service: "service"}
+			for _, optFn := range test.input.optFuncs {
+				optFn(c)
+			}
+			err := c.Validate()
+			if err != nil {
+				t.Contains(err.Error(), test.want)
+				return
+			}
+			t.Equal(test.want, err)
+		})
+	}
+}
+
+func (t *LoggerTestSuite) TestSinkLevels() {
+	var nilLevels *sinkLevels
+	t.True(nilLevels.Enabled(SinkSlack, logrus.TraceLevel))
+
+	levels := map[string]logrus.Level{SinkSlack: logrus.WarnLevel}
+	s := newSinkLevels(levels)
+	t.True(s.Enabled(SinkSlack, logrus.ErrorLevel))
+	t.True(s.Enabled(SinkSlack, logrus.WarnLevel))
+	t.False(s.Enabled(SinkSlack, logrus.InfoLevel))
+	t.True(s.Enabled(SinkMongo, logrus.TraceLevel))
+
+	s.Set(SinkMongo, logrus.ErrorLevel)
+	t.False(s.Enabled(SinkMongo, logrus.WarnLevel))
+	got, ok := s.Get(SinkMongo)
+	t.True(ok)
+	t.Equal(logrus.ErrorLevel, got)
+
+	// The levels passed should not be modified.
+	t.Len(levels, 1)
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_SinkLevels() {
+	var (
+		mtx      sync.Mutex
+		notified []logrus.Level
+		stored   []logrus.Level
+	)
+	hook := &defaultHook{
+		logger: logrus.New(),
+		levels: newSinkLevels(map[string]logrus.Level{
+			SinkSlack: logrus.ErrorLevel,
+			SinkMongo: logrus.InfoLevel,
+		}),
+		notifiers: []notifierConfig{
+			{Name: SinkSlack, Notifier: NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+				mtx.Lock()
+				defer mtx.Unlock()
+				notified = append(notified, entry.Level)
+				return nil
+			}), Report: types.DefaultReportFn},
+		},
+		mogrus: func(entry *logrus.Entry) error {
+			mtx.Lock()
+			defer mtx.Unlock()
+			stored = append(stored, entry.Level)
+			return nil
+		},
+		config: &Config{
+			mongo: mongoConfig{Report: types.DefaultReportFn},
+		},
+	}
+	hook.mongo = hook.newMongoQueue()
+
+	for _, level := range []logrus.Level{logrus.DebugLevel, logrus.InfoLevel, logrus.ErrorLevel} {
+		t.NoError(hook.Fire(&logrus.Entry{Level: level}))
+	}
+	t.NoError(hook.close(context.Background()))
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	t.Equal([]logrus.Level{logrus.ErrorLevel}, notified)
+	t.ElementsMatch([]logrus.Level{logrus.InfoLevel, logrus.ErrorLevel}, stored)
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_NotifierLevels() {
+	var (
+		mtx  sync.Mutex
+		sent = map[string][]logrus.Level{}
+	)
+	notifier := func(name string) notifierConfig {
+		return notifierConfig{
+			Name: name,
+			Notifier: NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+				mtx.Lock()
+				defer mtx.Unlock()
+				sent[name] = append(sent[name], entry.Level)
+				return nil
+			}),
+			Report: types.DefaultReportFn,
+		}
+	}
+
+	// Notifiers of the same type can have their own level.
+	hook := &defaultHook{
+		logger:    logrus.New(),
+		levels:    newSinkLevels(map[string]logrus.Level{"first": logrus.ErrorLevel}),
+		notifiers: []notifierConfig{notifier("first"), notifier("second")},
+		config:    &Config{},
+	}
+	for _, level := range []logrus.Level{logrus.InfoLevel, logrus.ErrorLevel} {
+		t.NoError(hook.Fire(&logrus.Entry{Level: level}))
+	}
+	t.NoError(hook.close(context.Background()))
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	t.Equal([]logrus.Level{logrus.ErrorLevel}, sent["first"])
+	t.ElementsMatch([]logrus.Level{logrus.InfoLevel, logrus.ErrorLevel}, sent["second"])
+}
+
+func (t *LoggerTestSuite) TestLogger_StdoutLevel() {
+	l, err := NewLogger(context.TODO(), NewOptions().
+		Service("service").
+		Level(logrus.DebugLevel).
+		SinkLevel(SinkStdout, logrus.WarnLevel))
+	t.NoError(err)
+	t.Equal(logrus.DebugLevel, l.GetLevel())
+
+	buf := &bytes.Buffer{}
+	for _, hooks := range l.Hooks {
+		for _, h := range hooks {
+			if h, ok := h.(*stdout.Hook); ok {
+				h.Writer = buf
+			}
+		}
+	}
+	l.Formatter.(*formatter).Colours = false
+
+	l.Info("info")
+	l.Warn("warn")
+	t.NotContains(buf.String(), "info")
+	t.Contains(buf.String(), "warn")
+}
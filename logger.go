@@ -22,14 +22,66 @@ import (
 	"os"
 )
 
+// Logger is an instance of the logger which owns its own
+// configuration, formatter and hooks. The underlying
+// logrus Logger is embedded so all standard logging
+// methods are available.
+type Logger struct {
+	*logrus.Logger
+	config *Config
+	hook   *defaultHook
+	levels *sinkLevels
+	// components are the loggers created with Component,
+	// each with their own level.
+	components components
+	// stopSignals stops changing the level on signals, only
+	// set if LevelSignals is enabled.
+	stopSignals func()
+}
+
+// Stats defines the counters for entries delivered by
+// a Logger.
+type Stats struct {
+	// MongoQueued is the amount of entries waiting to be
+	// written to Mongo.
+	MongoQueued int
+	// MongoDropped is the amount of entries discarded
+	// because the Mongo queue was full.
+	MongoDropped uint64
+	// DeadLetter is the amount of entries waiting to be
+	// replayed within the dead letter queue.
+	DeadLetter int
+}
+
 var (
-	// L is an alias for the standard logrus Logger.
-	L = logrus.New()
+	// std is the default Logger used by the package level
+	// helpers, it is replaced when New is called.
+	std = wrap(logrus.New())
+	// L is the logrus Logger of the default Logger.
+	//
+	// Deprecated: Use Default instead, L is updated when the
+	// default Logger is replaced but assigning to it has
+	// no effect.
+	L = std.Logger
 )
 
-// New creates a new standard L and sets logging levels
-// dependent on environment variables.
+// New creates a new standard Logger and assigns it as
+// the default instance used by the package level
+// helpers.
 func New(ctx context.Context, opts ...*Options) error {
+	l, err := NewLogger(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	SetDefault(l)
+	return nil
+}
+
+// NewLogger creates a new Logger instance with the given
+// options, independent of the default Logger.
+// Returns an error if the configuration failed validation
+// or any of the hooks could not be created.
+func NewLogger(ctx context.Context, opts ...*Options) (*Logger, error) {
 	c := &Config{}
 	for _, opt := range opts {
 		for _, optFn := range opt.optFuncs {
@@ -38,97 +90,193 @@ func New(ctx context.Context, opts ...*Options) error {
 	}
 	err := c.Validate()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	return initialise(ctx, c.assignDefaults())
 }
 
+// Default returns the default Logger used by the package
+// level helpers.
+func Default() *Logger {
+	return std
+}
+
 // Trace logs a trace message with args.
 func Trace(args ...any) {
-	L.Trace(args...)
+	std.Trace(args...)
 }
 
 // Debug logs a debug message with args.
 func Debug(args ...any) {
-	L.Debug(args...)
+	std.Debug(args...)
 }
 
 // Info logs ab info message with args.
 func Info(args ...any) {
-	L.Info(args...)
+	std.Info(args...)
 }
 
 // Warn logs a warn message with args.
 func Warn(args ...any) {
-	L.Warn(args...)
+	std.Warn(args...)
 }
 
 // Error logs an error message with args.
 func Error(args ...any) {
-	L.Error(args...)
+	std.Error(args...)
 }
 
 // Fatal logs a fatal message with args.
 func Fatal(args ...any) {
-	L.Fatal(args...)
+	std.Fatal(args...)
 }
 
 // Panic logs a panic message with args.
 func Panic(args ...any) {
-	L.Panic(args...)
+	std.Panic(args...)
 }
 
 // WithField logs with field, sets a new map containing
 // "fields".
 func WithField(key string, value any) *logrus.Entry {
-	return L.WithFields(logrus.Fields{types.FieldKey: logrus.Fields{
-		key: value,
-	}})
+	return std.WithField(key, value)
 }
 
 // WithFields logs with fields, sets a new map containing
 // "fields".
 func WithFields(fields types.Fields) *logrus.Entry {
-	return L.WithFields(logrus.Fields{types.FieldKey: fields})
+	return std.WithFields(fields)
 }
 
 // WithError - Logs with a custom error.
 func WithError(err any) *logrus.Entry {
-	return L.WithField(types.ErrorKey, err)
+	return std.WithError(err)
 }
 
-// SetOutput sets the output of the L to an io.Writer,
-// useful for testing.
+// SetOutput sets the output of the default Logger to an
+// io.Writer, useful for testing.
 func SetOutput(writer io.Writer) {
-	L.SetOutput(writer)
+	std.SetOutput(writer)
 }
 
-// SetLevel sets the level of the L.
+// SetLevel sets the level of the default Logger.
 func SetLevel(level logrus.Level) {
-	L.SetLevel(level)
+	std.SetLevel(level)
 }
 
-// SetLogger sets the application L.
+// SetLogger sets the logrus Logger used by the package
+// level helpers, it's wrapped in a Logger with the default
+// configuration and no hooks. Use SetDefault to set a
+// Logger created with NewLogger.
 func SetLogger(l *logrus.Logger) {
-	L = l
+	SetDefault(wrap(l))
 }
 
-// initialise sets the standard log level, sets the
-// log formatter and discards the stdout.
-func initialise(ctx context.Context, cfg *Config) error { //nolint
-	L.SetLevel(logrus.TraceLevel)
+// SetDefault sets the default Logger used by the package
+// level helpers.
+func SetDefault(l *Logger) {
+	std = l
+	L = l.Logger
+}
 
-	L.SetFormatter(&formatter{
-		Config:          cfg,
-		TimestampFormat: "2006-01-02 15:04:05",
-		Colours:         true,
-	})
+// wrap creates a Logger from a logrus Logger with the
+// default configuration.
+func wrap(l *logrus.Logger) *Logger {
+	return &Logger{
+		Logger: l,
+		config: (&Config{}).assignDefaults(),
+		levels: newSinkLevels(nil),
+	}
+}
+
+// Flush waits for all entries that are pending delivery
+// on the default Logger, see Logger.Flush.
+func Flush(ctx context.Context) error {
+	return std.Flush(ctx)
+}
+
+// Close flushes and closes the default Logger, see
+// Logger.Close.
+func Close(ctx context.Context) error {
+	return std.Close(ctx)
+}
+
+// Flush waits for all entries that are pending delivery
+// to Mongo, Slack and Workplace to complete.
+// Returns an error if the context is done before all
+// deliveries have finished.
+func (l *Logger) Flush(ctx context.Context) error {
+	if l.hook == nil {
+		return nil
+	}
+	return l.hook.flush(ctx)
+}
+
+// Close stops entries from being sent to Mongo, Slack and
+// Workplace and waits for the pending deliveries to
+// complete. Entries will still be written to stdout
+// after the Logger is closed.
+func (l *Logger) Close(ctx context.Context) error {
+	if l.config != nil && l.config.file != nil {
+		l.config.file.close()
+	}
+	if l.stopSignals != nil {
+		l.stopSignals()
+	}
+	if l.hook == nil {
+		return nil
+	}
+	return l.hook.close(ctx)
+}
+
+// Stats returns the delivery counters for the Logger.
+func (l *Logger) Stats() Stats {
+	if l.hook == nil {
+		return Stats{}
+	}
+	return l.hook.stats()
+}
+
+// WithField logs with field, sets a new map containing
+// "fields".
+func (l *Logger) WithField(key string, value any) *logrus.Entry {
+	return l.Logger.WithFields(logrus.Fields{types.FieldKey: logrus.Fields{
+		key: value,
+	}})
+}
+
+// WithFields logs with fields, sets a new map containing
+// "fields".
+func (l *Logger) WithFields(fields types.Fields) *logrus.Entry {
+	return l.Logger.WithFields(logrus.Fields{types.FieldKey: fields})
+}
+
+// WithError - Logs with a custom error.
+func (l *Logger) WithError(err any) *logrus.Entry {
+	return l.Logger.WithField(types.ErrorKey, err)
+}
+
+// initialise creates a new Logger, sets the standard log
+// level, sets the log formatter and discards the stdout.
+func initialise(ctx context.Context, cfg *Config) (*Logger, error) { //nolint
+	l := &Logger{
+		Logger: logrus.New(),
+		config: cfg,
+		levels: newSinkLevels(cfg.sinkLevels),
+	}
+	stdoutEnabled := func(level logrus.Level) bool {
+		return l.levels.Enabled(SinkStdout, level)
+	}
+
+	l.SetLevel(*cfg.level)
+
+	l.SetFormatter(newFormatter(cfg))
 
 	// Send all logs to nowhere by default.
-	L.SetOutput(io.Discard)
+	l.SetOutput(io.Discard)
 
 	// Send logs with level higher than warning to stderr.
-	L.AddHook(&stdout.Hook{
+	l.AddHook(&stdout.Hook{
 		Writer: os.Stderr,
 		LogLevels: []logrus.Level{
 			logrus.PanicLevel,
@@ -136,23 +284,33 @@ func initialise(ctx context.Context, cfg *Config) error { //nolint
 			logrus.ErrorLevel,
 			logrus.WarnLevel,
 		},
+		Enabled: stdoutEnabled,
 	})
 
 	// Send info and debug logs to stdout.
-	L.AddHook(&stdout.Hook{
+	l.AddHook(&stdout.Hook{
 		Writer: os.Stdout,
 		LogLevels: []logrus.Level{
 			logrus.TraceLevel,
 			logrus.InfoLevel,
 			logrus.DebugLevel,
 		},
+		Enabled: stdoutEnabled,
 	})
 
 	// Add the WP & Mogrus hooks to the logger.
-	err := addHooks(ctx, cfg)
+	err := addHooks(ctx, l)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	if cfg.file != nil {
+		cfg.file.watch(l)
+	}
+
+	if cfg.levelSignals {
+		l.stopSignals = l.watchSignals()
+	}
+
+	return l, nil
 }
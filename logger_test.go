@@ -24,13 +24,14 @@ import (
 	"github.com/ainsleyclark/mogrus"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/mongo"
+	"time"
 )
 
 func (t *LoggerTestSuite) TestNew() {
 	tt := map[string]struct {
 		input  func() *Options
 		mogrus func(ctx context.Context, opts mogrus.Options) (logrus.Hook, error)
-		hook   func(opts workplace.Options) (*workplace.Hook, error)
+		hook   func(opts workplace.Options) (*workplace.Notifier, error)
 		want   any
 	}{
 		"Simple": {
@@ -38,7 +39,7 @@ func (t *LoggerTestSuite) TestNew() {
 				return NewOptions().Service("service")
 			},
 			mogrus.New,
-			workplace.NewHook,
+			workplace.New,
 			nil,
 		},
 		"Validation Error": {
@@ -46,7 +47,7 @@ func (t *LoggerTestSuite) TestNew() {
 				return NewOptions()
 			},
 			mogrus.New,
-			workplace.NewHook,
+			workplace.New,
 			"service name cannot be empty",
 		},
 		"With Workplace": {
@@ -54,7 +55,7 @@ func (t *LoggerTestSuite) TestNew() {
 				return NewOptions().Service("service").WithWorkplaceNotifier("token", "thread", nil, nil)
 			},
 			mogrus.New,
-			workplace.NewHook,
+			workplace.New,
 			nil,
 		},
 		"Workplace Error": {
@@ -62,7 +63,7 @@ func (t *LoggerTestSuite) TestNew() {
 				return NewOptions().Service("service").WithWorkplaceNotifier("token", "thread", nil, nil)
 			},
 			mogrus.New,
-			func(opts workplace.Options) (*workplace.Hook, error) {
+			func(opts workplace.Options) (*workplace.Notifier, error) {
 				return nil, errors.New("hook error")
 			},
 			"hook error",
@@ -74,7 +75,17 @@ func (t *LoggerTestSuite) TestNew() {
 			func(ctx context.Context, opts mogrus.Options) (logrus.Hook, error) {
 				return &stdout.Hook{}, nil
 			},
-			workplace.NewHook,
+			workplace.New,
+			nil,
+		},
+		"With Mogrus Batch": {
+			func() *Options {
+				return NewOptions().Service("service").WithMongo(NewMongoOptions(&mongo.Collection{}).Batch(10, time.Second))
+			},
+			func(ctx context.Context, opts mogrus.Options) (logrus.Hook, error) {
+				return &stdout.Hook{}, nil
+			},
+			workplace.New,
 			nil,
 		},
 		"Mogrus Error": {
@@ -84,7 +95,7 @@ func (t *LoggerTestSuite) TestNew() {
 			func(ctx context.Context, opts mogrus.Options) (logrus.Hook, error) {
 				return nil, errors.New("mogrus error")
 			},
-			workplace.NewHook,
+			workplace.New,
 			"mogrus error",
 		},
 		"With Slack": {
@@ -92,7 +103,7 @@ func (t *LoggerTestSuite) TestNew() {
 				return NewOptions().Service("service").WithSlackNotifier("token", "channel", nil, nil)
 			},
 			mogrus.New,
-			workplace.NewHook,
+			workplace.New,
 			"mogrus error",
 		},
 	}
@@ -180,12 +191,79 @@ func (t *LoggerTestSuite) TestLogger() {
 	}
 }
 
+func (t *LoggerTestSuite) TestNewLogger() {
+	t.Run("Validation Error", func() {
+		_, err := NewLogger(context.TODO(), NewOptions())
+		t.ErrorContains(err, "service name cannot be empty")
+	})
+
+	t.Run("Independent Instances", func() {
+		a, err := NewLogger(context.TODO(), NewOptions().Service("a").Prefix("a"))
+		t.NoError(err)
+		b, err := NewLogger(context.TODO(), NewOptions().Service("b").Prefix("b"))
+		t.NoError(err)
+		t.NotSame(a.Logger, b.Logger)
+		t.Equal("a", a.config.service)
+		t.Equal("b", b.config.service)
+		t.NotSame(std, a)
+	})
+
+	t.Run("Level", func() {
+		l, err := NewLogger(context.TODO(), NewOptions().Service("service").Level(logrus.WarnLevel))
+		t.NoError(err)
+		t.Equal(logrus.WarnLevel, l.GetLevel())
+	})
+
+	t.Run("Env", func() {
+		t.T().Setenv("LOGGER_SERVICE", "service")
+		t.T().Setenv("LOGGER_FORMAT", "json")
+		l, err := NewLogger(context.TODO(), NewOptions().FromEnv(""))
+		t.NoError(err)
+		t.IsType(&jsonFormatter{}, l.Formatter)
+	})
+}
+
+func (t *LoggerTestSuite) TestLogger_Instance() {
+	buf := &bytes.Buffer{}
+	l, err := NewLogger(context.TODO(), NewOptions().Service("service").Prefix("instance"))
+	t.NoError(err)
+	l.SetOutput(buf)
+	l.Formatter.(*formatter).Colours = false
+
+	l.WithField("key", "value").Info("message")
+	t.Contains(buf.String(), "[INSTANCE]")
+	t.Contains(buf.String(), "key: value")
+
+	buf.Reset()
+	l.WithError(&errors.Error{Code: "code", Message: "message"}).Error()
+	t.Contains(buf.String(), "[code] code [msg] message")
+}
+
+func (t *LoggerTestSuite) TestLogger_Flush() {
+	t.Run("No Hooks", func() {
+		t.Setup()
+		t.NoError(Flush(context.TODO()))
+		t.NoError(Close(context.TODO()))
+	})
+
+	t.Run("With Hooks", func() {
+		l, err := NewLogger(context.TODO(), NewOptions().Service("service"))
+		t.NoError(err)
+		t.NoError(l.Flush(context.TODO()))
+		t.NoError(l.Close(context.TODO()))
+	})
+}
+
+func (t *LoggerTestSuite) TestLogger_Stats() {
+	t.Setup()
+	t.Equal(Stats{}, std.Stats())
+	std.hook = &defaultHook{}
+	t.Equal(Stats{}, std.Stats())
+}
+
 func (t *LoggerTestSuite) TestLogger_Fatal() {
 	buf := t.Setup() // nolint
-	defer func() {
-		L = logrus.New()
-	}()
-	L.ExitFunc = func(i int) {}
+	std.ExitFunc = func(i int) {}
 	Fatal("fatal")
 	t.Contains(buf.String(), "fatal")
 }
@@ -199,24 +277,35 @@ func (t *LoggerTestSuite) TestLogger_Panic() {
 }
 
 func (t *LoggerTestSuite) TestLogger_SetOutput() {
+	t.Setup()
 	buf := &bytes.Buffer{}
 	SetOutput(buf)
-	t.Equal(buf, L.Out)
+	t.Equal(buf, std.Out)
 }
 
 func (t *LoggerTestSuite) TestSetLevel() {
-	defer func() {
-		L = logrus.New()
-	}()
+	t.Setup()
 	SetLevel(logrus.WarnLevel)
-	t.Equal(logrus.WarnLevel, L.GetLevel())
+	t.Equal(logrus.WarnLevel, std.GetLevel())
 }
 
 func (t *LoggerTestSuite) TestSetLogger() {
-	defer func() {
-		L = logrus.New()
-	}()
-	l := L
+	orig := std
+	defer SetDefault(orig)
+	l := logrus.New()
 	SetLogger(l)
-	t.Equal(l, L)
+	t.Same(l, std.Logger)
+	t.Same(l, L)
+	t.NotNil(std.config)
+	t.Equal([]string{SinkMongo, SinkStdout}, std.Sinks())
+}
+
+func (t *LoggerTestSuite) TestSetDefault() {
+	orig := std
+	defer SetDefault(orig)
+	l := &Logger{Logger: logrus.New()}
+	SetDefault(l)
+	t.Same(l, std)
+	t.Same(l, Default())
+	t.Same(l.Logger, L)
 }
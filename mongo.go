@@ -0,0 +1,284 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/queue"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/ainsleyclark/mogrus"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// OverflowPolicy defines what happens to an entry when the
+// Mongo queue is full.
+type OverflowPolicy = queue.Policy
+
+const (
+	// OverflowBlock waits until there is space in the queue,
+	// applying backpressure to the caller.
+	OverflowBlock = queue.Block
+	// OverflowDropNewest discards the entry being logged.
+	OverflowDropNewest = queue.DropNewest
+	// OverflowDropOldest discards the oldest entry waiting
+	// in the queue.
+	OverflowDropOldest = queue.DropOldest
+)
+
+const (
+	// DefaultMongoQueueSize is the default amount of entries
+	// that can be waiting to be written to Mongo.
+	DefaultMongoQueueSize = 1024
+	// DefaultMongoWorkers is the default amount of workers
+	// writing entries to Mongo.
+	DefaultMongoWorkers = 1
+	// DefaultMongoBatchInterval is the default interval in
+	// which batched entries are written to Mongo.
+	DefaultMongoBatchInterval = time.Second * 5
+)
+
+// ExpirationLevels defines how long entries are kept
+// within Mongo for each level, levels that are not
+// within the map never expire.
+type ExpirationLevels = mogrus.ExpirationLevels
+
+// DefaultMongoExpiration returns the default expiration
+// levels used for Mongo entries, 24 hours for trace and
+// debug, a week for info, 4 weeks for warn and error
+// and 24 weeks for panic and fatal.
+func DefaultMongoExpiration() ExpirationLevels {
+	return ExpirationLevels{
+		logrus.TraceLevel: time.Hour * 24,
+		logrus.DebugLevel: time.Hour * 24,
+		logrus.InfoLevel:  time.Hour * 24 * 7,
+		logrus.ErrorLevel: time.Hour * 24 * 7 * 4,
+		logrus.WarnLevel:  time.Hour * 24 * 7 * 4,
+		logrus.PanicLevel: time.Hour * 24 * 7 * 4 * 6,
+		logrus.FatalLevel: time.Hour * 24 * 7 * 4 * 6,
+	}
+}
+
+// connectMongo connects to the URI within the config and
+// returns the collection entries are written to.
+func connectMongo(ctx context.Context, c mongoConfig) (*mongo.Client, *mongo.Collection, error) {
+	const op = "Logger.ConnectMongo"
+	client, err := mongoConnect(ctx, options.Client().ApplyURI(c.URI))
+	if err != nil {
+		return nil, nil, errors.NewInternal(err, "Error connecting to Mongo", op)
+	}
+	return client, client.Database(c.Database).Collection(c.Name), nil
+}
+
+// mongoInserter is the subset of mongo.Collection used
+// for writing batches of entries.
+type mongoInserter interface {
+	InsertMany(ctx context.Context, documents []any, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error)
+}
+
+// mongoOptionFunc is a function type that configures a
+// mongoConfig instance.
+type mongoOptionFunc func(config *mongoConfig)
+
+// MongoOptions is the type used to configure the Mongo sink.
+type MongoOptions struct {
+	optFuncs []mongoOptionFunc
+}
+
+// NewMongoOptions creates a MongoOptions instance that
+// logs to the collection passed.
+func NewMongoOptions(collection *mongo.Collection) *MongoOptions {
+	op := &MongoOptions{}
+	op.optFuncs = append(op.optFuncs, func(config *mongoConfig) {
+		config.Collection = collection
+	})
+	return op
+}
+
+// NewMongoURIOptions creates a MongoOptions instance that
+// connects to the URI passed when the logger is created
+// and logs to the database and collection. The client
+// is disconnected when the logger is closed.
+func NewMongoURIOptions(uri, database, collection string) *MongoOptions {
+	op := &MongoOptions{}
+	op.optFuncs = append(op.optFuncs, func(config *mongoConfig) {
+		config.URI = uri
+		config.Database = database
+		config.Name = collection
+	})
+	return op
+}
+
+// Report is the callback function to determine if the
+// entry should be stored within Mongo.
+func (op *MongoOptions) Report(fn types.ShouldReportFunc) *MongoOptions {
+	op.optFuncs = append(op.optFuncs, func(config *mongoConfig) {
+		config.Report = fn
+	})
+	return op
+}
+
+// Queue sets the maximum amount of entries waiting to be
+// written and the amount of workers writing them to
+// Mongo concurrently. Defaults to DefaultMongoQueueSize
+// and DefaultMongoWorkers.
+func (op *MongoOptions) Queue(size, workers int) *MongoOptions {
+	op.optFuncs = append(op.optFuncs, func(config *mongoConfig) {
+		config.QueueSize = size
+		config.Workers = workers
+	})
+	return op
+}
+
+// Overflow sets the policy used when the queue is full,
+// defaults to OverflowBlock.
+func (op *MongoOptions) Overflow(policy OverflowPolicy) *MongoOptions {
+	op.optFuncs = append(op.optFuncs, func(config *mongoConfig) {
+		config.Overflow = policy
+	})
+	return op
+}
+
+// Batch buffers entries and writes them to Mongo with a
+// single insert once size entries have been logged or
+// the interval has elapsed. The interval defaults to
+// DefaultMongoBatchInterval. Batching is disabled by
+// default, every entry is written individually.
+func (op *MongoOptions) Batch(size int, interval time.Duration) *MongoOptions {
+	op.optFuncs = append(op.optFuncs, func(config *mongoConfig) {
+		config.BatchSize = size
+		config.BatchInterval = interval
+	})
+	return op
+}
+
+// Expiration sets how long entries are kept within Mongo
+// for each level, replacing DefaultMongoExpiration.
+// Levels that are not within the map never expire.
+func (op *MongoOptions) Expiration(levels ExpirationLevels) *MongoOptions {
+	op.optFuncs = append(op.optFuncs, func(config *mongoConfig) {
+		config.Expiration = levels
+	})
+	return op
+}
+
+// DisableExpiration stops entries of the given levels from
+// expiring, so they are kept within Mongo indefinitely.
+func (op *MongoOptions) DisableExpiration(levels ...logrus.Level) *MongoOptions {
+	op.optFuncs = append(op.optFuncs, func(config *mongoConfig) {
+		config.NoExpiry = append(config.NoExpiry, levels...)
+	})
+	return op
+}
+
+// expirationLevels returns the expiration levels for the
+// configuration without the levels that have been
+// disabled.
+func (c mongoConfig) expirationLevels() ExpirationLevels {
+	levels := make(ExpirationLevels, len(c.Expiration))
+	for level, duration := range c.Expiration {
+		levels[level] = duration
+	}
+	for _, level := range c.NoExpiry {
+		delete(levels, level)
+	}
+	return levels
+}
+
+// writeMongoBatch writes the entries to the collection with
+// a single InsertMany call, expiry keys are added in the
+// same way as the mogrus hook.
+func writeMongoBatch(ctx context.Context, col mongoInserter, levels ExpirationLevels, entries []*logrus.Entry) error {
+	const op = "Logger.WriteMongoBatch"
+
+	docs := make([]any, len(entries))
+	for i, entry := range entries {
+		e := mogrus.ToEntry(entry)
+		if _, ok := levels[entry.Level]; ok {
+			e.Expiry[fmt.Sprintf(mogrus.DefaultExpiryKey, entry.Level.String())] = time.Now()
+		}
+		docs[i] = e
+	}
+
+	_, err := col.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		return errors.NewInternal(err, "Error writing entries to Mongo Collection", op)
+	}
+
+	return nil
+}
+
+const (
+	// mongoIndexNotFound is the code Mongo returns when
+	// modifying an index that doesn't exist.
+	mongoIndexNotFound = 27
+	// mongoIndexOptionsConflict is the code Mongo returns
+	// when an index already exists with different options,
+	// such as a TTL index with another duration.
+	mongoIndexOptionsConflict = 85
+)
+
+// modifyTTLIndex changes the expireAfterSeconds of the
+// index with the given key by running collMod.
+var modifyTTLIndex = func(ctx context.Context, col *mongo.Collection, key string, seconds int32) error {
+	return col.Database().RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: col.Name()},
+		{Key: "index", Value: bson.D{
+			{Key: "keyPattern", Value: bson.D{{Key: key, Value: 1}}},
+			{Key: "expireAfterSeconds", Value: seconds},
+		}},
+	}).Err()
+}
+
+// isIndexConflict determines if the error was returned by
+// Mongo for an index that exists with different options.
+func isIndexConflict(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.HasErrorCode(mongoIndexOptionsConflict)
+}
+
+// updateExpiration changes the duration of the existing TTL
+// indexes to match the expiration levels. Mongo doesn't
+// allow an index to be created again with a different
+// duration, so it is modified in place instead. Indexes
+// that don't exist yet are skipped.
+func updateExpiration(ctx context.Context, col *mongo.Collection, levels ExpirationLevels) error {
+	const op = "Logger.UpdateExpiration"
+
+	for _, level := range logrus.AllLevels {
+		duration, ok := levels[level]
+		if !ok {
+			continue
+		}
+		key := "expiry." + fmt.Sprintf(mogrus.DefaultExpiryKey, level.String())
+		seconds := int32(duration.Seconds())
+		err := modifyTTLIndex(ctx, col, key, seconds)
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && cmdErr.HasErrorCode(mongoIndexNotFound) {
+			continue
+		}
+		if err != nil {
+			msg := fmt.Sprintf("Error updating the duration of the TTL index %s, drop the index or run collMod "+
+				"with expireAfterSeconds set to %d", key, seconds)
+			return errors.NewInternal(err, msg, op)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/mogrus"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// fakeInserter records documents written with InsertMany.
+type fakeInserter struct {
+	docs []any
+	err  error
+}
+
+func (f *fakeInserter) InsertMany(_ context.Context, documents []any, _ ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.docs = append(f.docs, documents...)
+	return &mongo.InsertManyResult{}, nil
+}
+
+func (t *LoggerTestSuite) TestWriteMongoBatch() {
+	entries := []*logrus.Entry{
+		{Level: logrus.InfoLevel, Message: "info"},
+		{Level: logrus.ErrorLevel, Message: "error"},
+	}
+
+	t.Run("Success", func() {
+		f := &fakeInserter{}
+		levels := mogrus.ExpirationLevels{logrus.ErrorLevel: time.Hour}
+		err := writeMongoBatch(context.TODO(), f, levels, entries)
+		t.NoError(err)
+		t.Len(f.docs, 2)
+
+		info := f.docs[0].(mogrus.Entry)
+		t.Equal("info", info.Message)
+		t.Empty(info.Expiry)
+
+		e := f.docs[1].(mogrus.Entry)
+		t.Equal("error", e.Message)
+		t.Contains(e.Expiry, fmt.Sprintf(mogrus.DefaultExpiryKey, "error"))
+	})
+
+	t.Run("Error", func() {
+		f := &fakeInserter{err: errors.New("insert error")}
+		err := writeMongoBatch(context.TODO(), f, nil, entries)
+		t.ErrorContains(err, "insert error")
+	})
+}
@@ -0,0 +1,354 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/digest"
+	"github.com/ainsleyclark/logger/internal/hooks/discord"
+	"github.com/ainsleyclark/logger/internal/hooks/email"
+	"github.com/ainsleyclark/logger/internal/hooks/pagerduty"
+	"github.com/ainsleyclark/logger/internal/hooks/slack"
+	"github.com/ainsleyclark/logger/internal/hooks/teams"
+	"github.com/ainsleyclark/logger/internal/hooks/webhook"
+	"github.com/ainsleyclark/logger/internal/hooks/workplace"
+	"github.com/ainsleyclark/logger/internal/ratelimit"
+	"github.com/ainsleyclark/logger/internal/retry"
+	"github.com/ainsleyclark/logger/types"
+)
+
+var (
+	// newEmail is an alias for email.New
+	newEmail = email.New
+	// newPagerDuty is an alias for pagerduty.New
+	newPagerDuty = pagerduty.New
+	// newWebhook is an alias for webhook.New
+	newWebhook = webhook.New
+)
+
+// RetryPolicy defines how failed deliveries to notifiers
+// are retried, with an exponential backoff between
+// attempts.
+type RetryPolicy = retry.Policy
+
+// DefaultRetryPolicy returns the retry policy used when
+// none is set, three attempts with a backoff starting
+// at half a second, up to 30 seconds with 20% jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return retry.Default()
+}
+
+type (
+	// Notifier defines a destination that log entries are
+	// sent to, such as a chat channel or thread.
+	Notifier interface {
+		// Send delivers the entry to the destination. The args
+		// contain the service information along with the
+		// formatter registered with the notifier, calling
+		// args.Message(entry) returns the formatted message.
+		Send(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error
+	}
+	// NotifierFunc is an adapter to allow the use of ordinary
+	// functions as a Notifier.
+	NotifierFunc func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error
+	// notifierConfig is the configuration used to send
+	// entries to a Notifier.
+	notifierConfig struct {
+		Name      string
+		Notifier  Notifier
+		Report    types.ShouldReportFunc
+		Formatter types.FormatMessageFunc
+		// limiter suppresses similar entries, only set if
+		// rate limiting is enabled.
+		limiter *ratelimit.Limiter
+		// digest collects entries to be sent periodically,
+		// only set if digests are enabled.
+		digest *digest.Aggregator
+	}
+)
+
+// Send calls f(ctx, entry, args).
+func (f NotifierFunc) Send(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+	return f(ctx, entry, args)
+}
+
+// WithNotifier sends entries to the Notifier passed. The
+// notifier is named after its type, such as
+// "logger.NotifierFunc", with a numbered suffix if the
+// name has already been used, "logger.NotifierFunc-2".
+// Use WithNamedNotifier to refer to the notifier by a
+// name of your own, such as with SinkLevel. The report
+// function determines if an entry should be sent and
+// the formatter is passed to the Notifier with the
+// FormatMessageArgs, both can be nil.
+func (op *Options) WithNotifier(n Notifier, fn types.ShouldReportFunc, formatter types.FormatMessageFunc) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.notifiers = append(config.notifiers, notifierConfig{
+			Name:      config.defaultNotifierName(n),
+			Notifier:  n,
+			Report:    fn,
+			Formatter: formatter,
+		})
+	})
+	return op
+}
+
+// WithNamedNotifier sends entries to the Notifier passed in
+// the same way as WithNotifier. The name must be unique,
+// it's used to refer to the notifier such as with
+// SinkLevel.
+func (op *Options) WithNamedNotifier(name string, n Notifier, fn types.ShouldReportFunc, formatter types.FormatMessageFunc) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.notifiers = append(config.notifiers, notifierConfig{
+			Name:      name,
+			Notifier:  n,
+			Report:    fn,
+			Formatter: formatter,
+		})
+	})
+	return op
+}
+
+// defaultNotifierName returns the name of the Notifier's
+// type, suffixed with a number if a notifier with the
+// same name has already been registered.
+func (c *Config) defaultNotifierName(n Notifier) string {
+	name := fmt.Sprintf("%T", n)
+	used := make(map[string]bool, len(c.notifiers))
+	for _, existing := range c.notifiers {
+		used[existing.Name] = true
+	}
+	if !used[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		suffixed := fmt.Sprintf("%s-%d", name, i)
+		if !used[suffixed] {
+			return suffixed
+		}
+	}
+}
+
+// addNotifiers adds the built-in notifiers if they have
+// been configured, followed by the notifiers registered
+// with WithNotifier and WithNamedNotifier.
+func (hook *defaultHook) addNotifiers() error {
+	var notifiers []notifierConfig
+
+	if hook.config.workplace.enabled() {
+		wp, err := newWP(workplace.Options{
+			Token:  hook.config.workplace.Token,
+			Thread: hook.config.workplace.Thread,
+		})
+		if err != nil {
+			return err
+		}
+		notifiers = append(notifiers, notifierConfig{
+			Name:      SinkWorkplace,
+			Notifier:  wp,
+			Report:    hook.config.workplace.Report,
+			Formatter: hook.config.workplace.Formatter,
+		})
+	}
+
+	if hook.config.slack.enabled() {
+		notifiers = append(notifiers, notifierConfig{
+			Name: SinkSlack,
+			Notifier: slack.New(slack.Options{
+				Token:        hook.config.slack.Token,
+				Channel:      hook.config.slack.Channel,
+				WebhookURL:   hook.config.slack.WebhookURL,
+				Blocks:       hook.config.slack.Blocks,
+				ThreadWindow: hook.config.slack.Thread,
+				OnError:      hook.logError,
+			}),
+			Report:    hook.config.slack.Report,
+			Formatter: hook.config.slack.Formatter,
+		})
+	}
+
+	if hook.config.teams.enabled() {
+		notifiers = append(notifiers, notifierConfig{
+			Name:      SinkTeams,
+			Notifier:  teams.New(teams.Options{URL: hook.config.teams.URL}),
+			Report:    hook.config.teams.Report,
+			Formatter: hook.config.teams.Formatter,
+		})
+	}
+
+	if hook.config.discord.enabled() {
+		notifiers = append(notifiers, notifierConfig{
+			Name:      SinkDiscord,
+			Notifier:  discord.New(discord.Options{URL: hook.config.discord.URL}),
+			Report:    hook.config.discord.Report,
+			Formatter: hook.config.discord.Formatter,
+		})
+	}
+
+	if hook.config.email.enabled() {
+		n, err := newEmail(hook.config.email.Options)
+		if err != nil {
+			return err
+		}
+		notifiers = append(notifiers, notifierConfig{
+			Name:      SinkEmail,
+			Notifier:  n,
+			Report:    hook.config.email.Report,
+			Formatter: hook.config.email.Formatter,
+		})
+	}
+
+	if hook.config.pagerDuty.enabled() {
+		n, err := newPagerDuty(hook.config.pagerDuty.Options)
+		if err != nil {
+			return err
+		}
+		notifiers = append(notifiers, notifierConfig{
+			Name:      SinkPagerDuty,
+			Notifier:  n,
+			Report:    hook.config.pagerDuty.Report,
+			Formatter: hook.config.pagerDuty.Formatter,
+		})
+	}
+
+	for _, wh := range hook.config.webhooks {
+		n, err := newWebhook(wh.Options)
+		if err != nil {
+			return err
+		}
+		notifiers = append(notifiers, notifierConfig{
+			Name:      wh.name(),
+			Notifier:  n,
+			Report:    wh.Report,
+			Formatter: wh.Formatter,
+		})
+	}
+
+	hook.notifiers = append(notifiers, hook.config.notifiers...)
+	hook.addLimiters()
+	hook.addDigests()
+
+	return nil
+}
+
+// notifierNames returns the names of the notifiers that
+// have been configured, in the order they are added by
+// addNotifiers.
+func (c *Config) notifierNames() []string {
+	var names []string
+	if c.workplace.enabled() {
+		names = append(names, SinkWorkplace)
+	}
+	if c.slack.enabled() {
+		names = append(names, SinkSlack)
+	}
+	if c.teams.enabled() {
+		names = append(names, SinkTeams)
+	}
+	if c.discord.enabled() {
+		names = append(names, SinkDiscord)
+	}
+	if c.email.enabled() {
+		names = append(names, SinkEmail)
+	}
+	if c.pagerDuty.enabled() {
+		names = append(names, SinkPagerDuty)
+	}
+	for _, wh := range c.webhooks {
+		names = append(names, wh.name())
+	}
+	for _, n := range c.notifiers {
+		names = append(names, n.Name)
+	}
+	return names
+}
+
+// validateNotifierNames checks that every notifier has a
+// name that doesn't clash with another sink.
+func (c *Config) validateNotifierNames() error {
+	seen := map[string]bool{SinkStdout: true, SinkMongo: true}
+	for _, name := range c.notifierNames() {
+		if name == "" {
+			return errors.New("notifier name cannot be empty")
+		}
+		if seen[name] {
+			return fmt.Errorf("notifier name %s is used more than once", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// notifier returns the notifier with the name passed.
+func (hook *defaultHook) notifier(name string) (notifierConfig, bool) {
+	for _, n := range hook.notifiers {
+		if n.Name == name {
+			return n, true
+		}
+	}
+	return notifierConfig{}, false
+}
+
+// notify sends the entry to every notifier that it should
+// be reported to, unless it has been rate limited or
+// is waiting to be sent within a digest.
+func (hook *defaultHook) notify(entry types.Entry) {
+	for _, n := range hook.notifiers {
+		if !hook.levels.Enabled(n.Name, entry.Level) || !n.Report(entry) {
+			continue
+		}
+		if n.digest != nil && hook.config.digest.Report(entry) {
+			n.digest.Add(entry)
+			continue
+		}
+		if n.limiter != nil && !n.limiter.Allow(entry) {
+			continue
+		}
+		hook.deliver(n, entry)
+	}
+}
+
+// deliver sends the entry to the notifier in the
+// background. Failed deliveries are retried with the
+// retry policy, permanent failures are not stored in
+// the dead letter queue as they would never succeed.
+func (hook *defaultHook) deliver(n notifierConfig, entry types.Entry) {
+	const op = "Logger.Notify"
+	hook.pending.Go(func() {
+		err := hook.config.retry.Do(hook.deliveryContext(), func(ctx context.Context) error {
+			return n.Notifier.Send(ctx, entry, hook.args(n))
+		})
+		if err != nil {
+			hook.logError(errors.NewInternal(err, "Error sending entry to "+n.Name, op)) // Don't return, still have processing to do.
+			if !retry.IsPermanent(err) {
+				hook.deadLetter(n.Name, entry)
+			}
+			return
+		}
+		hook.recovered()
+	})
+}
+
+// args returns the FormatMessageArgs passed to the
+// notifier when sending an entry.
+func (hook *defaultHook) args(n notifierConfig) types.FormatMessageArgs {
+	return types.FormatMessageArgs{
+		Service:   hook.config.service,
+		Version:   hook.config.version,
+		Prefix:    hook.config.prefix,
+		Formatter: n.Formatter,
+	}
+}
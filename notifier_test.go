@@ -0,0 +1,250 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"sync/atomic"
+	"time"
+)
+
+func (t *LoggerTestSuite) TestOptions_WithNotifier() {
+	n := NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		return nil
+	})
+	opts := NewOptions().
+		WithNotifier(n, nil, nil).
+		WithNamedNotifier("custom", n, nil, nil).
+		WithNotifier(n, nil, nil)
+
+	c := &Config{service: "service"}
+	for _, optFn := range opts.optFuncs {
+		optFn(c)
+	}
+	c.assignDefaults()
+
+	t.NoError(c.Validate())
+	t.Len(c.notifiers, 3)
+	t.Equal("logger.NotifierFunc", c.notifiers[0].Name)
+	t.Equal("custom", c.notifiers[1].Name)
+	t.Equal("logger.NotifierFunc-2", c.notifiers[2].Name)
+	t.NotNil(c.notifiers[0].Report)
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_AddNotifiers() {
+	custom := NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		return nil
+	})
+	cfg := &Config{
+		slack:     slackConfig{Token: "token", Channel: "channel"},
+		teams:     teamsConfig{URL: "url"},
+		discord:   discordConfig{URL: "url"},
+		notifiers: []notifierConfig{{Name: "custom", Notifier: custom}},
+	}
+	hook := &defaultHook{config: cfg.assignDefaults()}
+
+	t.NoError(hook.addNotifiers())
+	t.Len(hook.notifiers, 4)
+	t.Equal("slack", hook.notifiers[0].Name)
+	t.Equal("teams", hook.notifiers[1].Name)
+	t.Equal("discord", hook.notifiers[2].Name)
+	t.Equal("custom", hook.notifiers[3].Name)
+}
+
+func (t *LoggerTestSuite) TestConfig_NotifierNames() {
+	n := NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+		return nil
+	})
+
+	tt := map[string]struct {
+		input *Options
+		want  any
+	}{
+		"Unique": {
+			NewOptions().
+				WithSlackNotifier("token", "channel", nil, nil).
+				WithWebhookNotifier(NewWebhookOptions("http://localhost")).
+				WithWebhookNotifier(NewWebhookOptions("http://localhost").Name("incidents")).
+				WithNamedNotifier("custom", n, nil, nil),
+			[]string{SinkSlack, SinkWebhook, "incidents", "custom"},
+		},
+		"Duplicate Webhooks": {
+			NewOptions().
+				WithWebhookNotifier(NewWebhookOptions("http://localhost")).
+				WithWebhookNotifier(NewWebhookOptions("http://localhost:8080")),
+			"notifier name webhook is used more than once",
+		},
+		"Duplicate Notifiers": {
+			NewOptions().
+				WithNamedNotifier("custom", n, nil, nil).
+				WithNamedNotifier("custom", n, nil, nil),
+			"notifier name custom is used more than once",
+		},
+		"Built In": {
+			NewOptions().
+				WithSlackNotifier("token", "channel", nil, nil).
+				WithNamedNotifier(SinkSlack, n, nil, nil),
+			"notifier name slack is used more than once",
+		},
+		"Reserved": {
+			NewOptions().WithNamedNotifier(SinkMongo, n, nil, nil),
+			"notifier name mongo is used more than once",
+		},
+		"Empty": {
+			NewOptions().WithNamedNotifier("", n, nil, nil),
+			"notifier name cannot be empty",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			c := &Config{service: "service"}
+			for _, optFn := range test.input.optFuncs {
+				optFn(c)
+			}
+			err := c.Validate()
+			if err != nil {
+				t.Contains(err.Error(), test.want)
+				return
+			}
+			t.Equal(test.want, c.notifierNames())
+		})
+	}
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_Notify() {
+	buf := &bytes.Buffer{}
+	l := logrus.New()
+	l.SetOutput(buf)
+
+	var got types.FormatMessageArgs
+	hook := &defaultHook{
+		logger: l,
+		config: &Config{service: "service", version: "v0.0.1", prefix: "prefix"},
+		notifiers: []notifierConfig{
+			{
+				Name: "notifier",
+				Notifier: NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+					got = args
+					return errors.New("send error")
+				}),
+				Report: types.DefaultReportFn,
+			},
+		},
+	}
+
+	hook.notify(types.Entry{Level: logrus.ErrorLevel})
+	t.NoError(hook.flush(context.Background()))
+
+	t.Equal("service", got.Service)
+	t.Equal("v0.0.1", got.Version)
+	t.Equal("prefix", got.Prefix)
+	t.Contains(buf.String(), "Error sending entry to notifier")
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_NotifyRetry() {
+	var (
+		attempts int32
+		got      error
+	)
+	hook := &defaultHook{
+		config: &Config{
+			retry: RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond},
+			onError: func(err error) {
+				got = err
+			},
+		},
+		notifiers: []notifierConfig{
+			{
+				Name: "notifier",
+				Notifier: NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+					atomic.AddInt32(&attempts, 1)
+					return errors.New("send error")
+				}),
+				Report: types.DefaultReportFn,
+			},
+		},
+	}
+
+	hook.notify(types.Entry{Level: logrus.ErrorLevel})
+	t.NoError(hook.flush(context.Background()))
+
+	t.Equal(int32(3), atomic.LoadInt32(&attempts))
+	t.ErrorContains(got, "Error sending entry to notifier")
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_NotifyRetrySuccess() {
+	var attempts int32
+	hook := &defaultHook{
+		config: &Config{
+			retry: RetryPolicy{MaxAttempts: 3},
+			onError: func(err error) {
+				t.Fail("error callback should not be called", err)
+			},
+		},
+		notifiers: []notifierConfig{
+			{
+				Name: "notifier",
+				Notifier: NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+					if atomic.AddInt32(&attempts, 1) == 1 {
+						return errors.New("send error")
+					}
+					return nil
+				}),
+				Report: types.DefaultReportFn,
+			},
+		},
+	}
+
+	hook.notify(types.Entry{Level: logrus.ErrorLevel})
+	t.NoError(hook.flush(context.Background()))
+	t.Equal(int32(2), atomic.LoadInt32(&attempts))
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_NotifyRetryClosed() {
+	var attempts int32
+	hook := &defaultHook{
+		config: &Config{
+			retry:   RetryPolicy{MaxAttempts: 10, BaseBackoff: time.Hour},
+			onError: func(err error) {},
+		},
+		notifiers: []notifierConfig{
+			{
+				Name: "notifier",
+				Notifier: NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+					atomic.AddInt32(&attempts, 1)
+					return errors.New("send error")
+				}),
+				Report: types.DefaultReportFn,
+			},
+		},
+	}
+
+	hook.notify(types.Entry{Level: logrus.ErrorLevel})
+
+	// The backoff outlasts the deadline, closing cancels it
+	// so the delivery doesn't keep running.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	t.Error(hook.close(ctx))
+
+	wait, cancelWait := context.WithTimeout(context.Background(), time.Second)
+	defer cancelWait()
+	t.NoError(hook.pending.Wait(wait))
+	t.Equal(int32(1), atomic.LoadInt32(&attempts))
+}
@@ -14,9 +14,15 @@
 package logger
 
 import (
+	"fmt"
 	"github.com/ainsleyclark/errors"
 	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/mongo"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type (
@@ -27,14 +33,43 @@ type (
 		prefix        string
 		defaultStatus string
 		service       string
+		level         *logrus.Level
+		sinkLevels    map[string]logrus.Level
+		levelSources  map[string]string
+		format        Format
+		colour        ColourMode
 		mongo         mongoConfig
 		workplace     workplaceConfig
 		slack         slackConfig
+		teams         teamsConfig
+		discord       discordConfig
+		email         emailConfig
+		pagerDuty     pagerDutyConfig
+		webhooks      []webhookConfig
+		notifiers     []notifierConfig
+		retry         RetryPolicy
+		onError       func(err error)
+		deadLetter    deadLetterConfig
+		rateLimit     rateLimitConfig
+		digest        digestConfig
+		optErr        error
+		file          *fileSource
+		levelSignals  bool
 	}
 	// mongoConfig is the configuration used to send to Mongo.
 	mongoConfig struct {
-		Collection *mongo.Collection
-		Report     types.ShouldReportFunc
+		Collection    *mongo.Collection
+		URI           string
+		Database      string
+		Name          string
+		Report        types.ShouldReportFunc
+		QueueSize     int
+		Workers       int
+		Overflow      OverflowPolicy
+		BatchSize     int
+		BatchInterval time.Duration
+		Expiration    ExpirationLevels
+		NoExpiry      []logrus.Level
 	}
 	// workplaceConfig is the configuration used to send to Workplace.
 	workplaceConfig struct {
@@ -45,13 +80,82 @@ type (
 	}
 	// slackConfig is the configuration used to send to Slack.
 	slackConfig struct {
-		Token     string
-		Channel   string
+		Token      string
+		Channel    string
+		WebhookURL string
+		Report     types.ShouldReportFunc
+		Formatter  types.FormatMessageFunc
+		Blocks     SlackBlockBuilder
+		Thread     time.Duration
+	}
+	// teamsConfig is the configuration used to send to
+	// Microsoft Teams.
+	teamsConfig struct {
+		URL       string
+		Report    types.ShouldReportFunc
+		Formatter types.FormatMessageFunc
+	}
+	// discordConfig is the configuration used to send to
+	// Discord.
+	discordConfig struct {
+		URL       string
 		Report    types.ShouldReportFunc
 		Formatter types.FormatMessageFunc
 	}
 )
 
+// enabled returns true if Workplace has been configured.
+func (c workplaceConfig) enabled() bool {
+	return c.Token != "" && c.Thread != ""
+}
+
+// enabled returns true if Slack has been configured with
+// a token and channel or a webhook.
+func (c slackConfig) enabled() bool {
+	return (c.Token != "" && c.Channel != "") || c.WebhookURL != ""
+}
+
+// enabled returns true if Teams has been configured.
+func (c teamsConfig) enabled() bool {
+	return c.URL != ""
+}
+
+// enabled returns true if Discord has been configured.
+func (c discordConfig) enabled() bool {
+	return c.URL != ""
+}
+
+// Format defines the output format of entries written
+// to stdout and stderr.
+type Format int
+
+const (
+	// FormatText is the default coloured, human-readable
+	// output format.
+	FormatText Format = iota
+	// FormatJSON writes each entry as a single line JSON
+	// object.
+	FormatJSON
+	// FormatLogfmt writes each entry as a single line of
+	// key=value pairs.
+	FormatLogfmt
+)
+
+// ColourMode defines when the text output format is
+// coloured.
+type ColourMode int
+
+const (
+	// ColourAuto colours the output if the terminal supports
+	// it, this is the default.
+	ColourAuto ColourMode = iota
+	// ColourAlways colours the output regardless of the
+	// terminal.
+	ColourAlways
+	// ColourNever disables colours.
+	ColourNever
+)
+
 const (
 	// DefaultPrefix is the default prefix used when none
 	// is set.
@@ -64,15 +168,109 @@ const (
 // Validate ensures the configuration is sanity checked
 // before creating a new Logger.
 func (c *Config) Validate() error {
+	if c.optErr != nil {
+		return c.optErr
+	}
 	if c.service == "" {
 		return errors.New("service name cannot be empty")
 	}
+	if c.level != nil && *c.level > logrus.TraceLevel {
+		return errors.New("level is invalid")
+	}
 	if c.workplace.Token != "" && c.workplace.Thread == "" {
 		return errors.New("workplace thread cannot be nil")
 	}
 	if c.workplace.Token == "" && c.workplace.Thread != "" {
 		return errors.New("workplace token cannot be nil")
 	}
+	if c.slack.WebhookURL != "" {
+		if c.slack.Token != "" {
+			return errors.New("slack token and webhook url cannot both be set")
+		}
+		if !validURL(c.slack.WebhookURL) {
+			return errors.New("slack webhook url must be a valid http or https url")
+		}
+		if c.slack.Thread > 0 {
+			return errors.New("slack threading is not available with webhooks")
+		}
+	}
+	if c.slack.Thread < 0 {
+		return errors.New("slack thread window cannot be negative")
+	}
+	if c.retry.MaxAttempts < 0 {
+		return errors.New("retry max attempts cannot be negative")
+	}
+	if c.retry.BaseBackoff < 0 || c.retry.MaxBackoff < 0 {
+		return errors.New("retry backoff cannot be negative")
+	}
+	if c.retry.Jitter < 0 || c.retry.Jitter > 1 {
+		return errors.New("retry jitter must be between 0 and 1")
+	}
+	if c.rateLimit.Limit < 0 {
+		return errors.New("rate limit cannot be negative")
+	}
+	if c.rateLimit.Window < 0 {
+		return errors.New("rate limit window cannot be negative")
+	}
+	if c.digest.Window < 0 {
+		return errors.New("digest window cannot be negative")
+	}
+	if c.deadLetter.MaxSize < 0 {
+		return errors.New("dead letter max size cannot be negative")
+	}
+	if c.mongo.URI != "" && c.mongo.Database == "" {
+		return errors.New("mongo database cannot be empty")
+	}
+	if c.mongo.URI != "" && c.mongo.Name == "" {
+		return errors.New("mongo collection cannot be empty")
+	}
+	if c.mongo.QueueSize < 0 {
+		return errors.New("mongo queue size cannot be negative")
+	}
+	if c.mongo.Workers < 0 {
+		return errors.New("mongo workers cannot be negative")
+	}
+	if c.mongo.BatchSize < 0 {
+		return errors.New("mongo batch size cannot be negative")
+	}
+	if c.mongo.BatchInterval < 0 {
+		return errors.New("mongo batch interval cannot be negative")
+	}
+	if c.email.Options.Host != "" {
+		if c.email.Options.From == "" {
+			return errors.New("email from address cannot be empty")
+		}
+		if len(c.email.Options.To) == 0 {
+			return errors.New("email must have at least one recipient")
+		}
+		if c.email.Options.Timeout < 0 {
+			return errors.New("email timeout cannot be negative")
+		}
+	}
+	if c.pagerDuty.Options.Timeout < 0 {
+		return errors.New("pagerduty timeout cannot be negative")
+	}
+	for _, wh := range c.webhooks {
+		if wh.Options.URL == "" {
+			return errors.New("webhook url cannot be empty")
+		}
+		if wh.Options.Timeout < 0 {
+			return errors.New("webhook timeout cannot be negative")
+		}
+	}
+	err := c.validateNotifierNames()
+	if err != nil {
+		return err
+	}
+	err = c.validateSinkLevels()
+	if err != nil {
+		return err
+	}
+	for level, duration := range c.mongo.Expiration {
+		if duration < time.Second {
+			return fmt.Errorf("mongo expiration for level %s must be at least one second", level)
+		}
+	}
 	return nil
 }
 
@@ -85,15 +283,68 @@ func (c *Config) assignDefaults() *Config {
 	if c.defaultStatus == "" {
 		c.defaultStatus = DefaultStatus
 	}
+	if c.level == nil {
+		level := logrus.TraceLevel
+		c.level = &level
+	}
+	if c.retry == (RetryPolicy{}) {
+		c.retry = DefaultRetryPolicy()
+	}
+	if c.digest.Report == nil {
+		c.digest.Report = types.DefaultReportFn
+	}
+	if c.deadLetter.MaxSize == 0 {
+		c.deadLetter.MaxSize = DefaultDeadLetterMaxSize
+	}
+	if c.deadLetter.ReplayInterval == 0 {
+		c.deadLetter.ReplayInterval = DefaultDeadLetterReplayInterval
+	}
 	if c.workplace.Report == nil {
 		c.workplace.Report = types.DefaultReportFn
 	}
 	if c.mongo.Report == nil {
 		c.mongo.Report = types.DefaultReportFn
 	}
+	if c.mongo.QueueSize == 0 {
+		c.mongo.QueueSize = DefaultMongoQueueSize
+	}
+	if c.mongo.Workers == 0 {
+		c.mongo.Workers = DefaultMongoWorkers
+	}
+	if c.mongo.Expiration == nil {
+		c.mongo.Expiration = DefaultMongoExpiration()
+	}
+	if c.mongo.BatchSize > 0 && c.mongo.BatchInterval == 0 {
+		c.mongo.BatchInterval = DefaultMongoBatchInterval
+	}
 	if c.slack.Report == nil {
 		c.slack.Report = types.DefaultReportFn
 	}
+	if c.teams.Report == nil {
+		c.teams.Report = types.DefaultReportFn
+	}
+	if c.discord.Report == nil {
+		c.discord.Report = types.DefaultReportFn
+	}
+	if c.email.Report == nil {
+		c.email.Report = types.DefaultReportFn
+	}
+	if c.pagerDuty.Report == nil {
+		c.pagerDuty.Report = PagerDutyReportFn
+	}
+	for i := range c.webhooks {
+		if c.webhooks[i].Report == nil {
+			c.webhooks[i].Report = types.DefaultReportFn
+		}
+	}
+	for i := range c.notifiers {
+		if c.notifiers[i].Report == nil {
+			c.notifiers[i].Report = types.DefaultReportFn
+		}
+	}
+	if c.file != nil {
+		c.file.bind(c)
+	}
 	return c
 }
 
@@ -147,13 +398,68 @@ func (op *Options) Service(service string) *Options {
 	return op
 }
 
+// Format sets the output format of the entries written to
+// stdout and stderr, defaults to FormatText.
+func (op *Options) Format(format Format) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.format = format
+	})
+	return op
+}
+
+// Level sets the minimum level of entries that are logged,
+// defaults to logrus.TraceLevel. See SinkLevel for
+// setting the level of each destination.
+func (op *Options) Level(level logrus.Level) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.level = &level
+	})
+	return op
+}
+
+// Colour sets when the text output format is coloured,
+// defaults to ColourAuto.
+func (op *Options) Colour(mode ColourMode) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.colour = mode
+	})
+	return op
+}
+
+// Retry sets the policy used to retry failed deliveries to
+// notifiers, defaults to DefaultRetryPolicy. Set the
+// maximum attempts to one to disable retries.
+func (op *Options) Retry(policy RetryPolicy) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.retry = policy
+	})
+	return op
+}
+
+// OnError is called with any error that occurs delivering
+// an entry, such as a notifier failing after all retries
+// or writing to Mongo. By default, errors are logged to
+// stderr.
+func (op *Options) OnError(fn func(err error)) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.onError = fn
+	})
+	return op
+}
+
 // WithMongoCollection allows for logging directly to Mongo.
+// See WithMongo for configuring the Mongo sink further.
 func (op *Options) WithMongoCollection(collection *mongo.Collection, fn types.ShouldReportFunc) *Options {
-	// TODO, Mongo options should be its own func constructor.
+	return op.WithMongo(NewMongoOptions(collection).Report(fn))
+}
+
+// WithMongo allows for logging directly to Mongo with the
+// Mongo specific options passed.
+func (op *Options) WithMongo(opts *MongoOptions) *Options {
 	op.optFuncs = append(op.optFuncs, func(config *Config) {
-		config.mongo = mongoConfig{
-			Collection: collection,
-			Report:     fn,
+		config.mongo = mongoConfig{}
+		for _, optFn := range opts.optFuncs {
+			optFn(&config.mongo)
 		}
 	})
 	return op
@@ -175,16 +481,114 @@ func (op *Options) WithWorkplaceNotifier(token, thread string, fn types.ShouldRe
 }
 
 // WithSlackNotifier sends errors that have been marked
-// as errors.INTERNAL to a Workplace thread.
+// as errors.INTERNAL to a Slack channel. See WithSlack
+// for configuring the Slack notifier further.
 func (op *Options) WithSlackNotifier(token, channel string, fn types.ShouldReportFunc, formatter types.FormatMessageFunc) *Options {
-	// TODO, Slack options should be its own func constructor.
+	return op.WithSlack(NewSlackOptions(token, channel).Report(fn).Formatter(formatter))
+}
+
+// WithTeamsNotifier sends errors that have been marked as
+// errors.INTERNAL to a Microsoft Teams channel via the
+// incoming webhook URL passed. Entries are rendered as
+// an Adaptive Card, the formatted message is only added
+// to the card if a formatter is passed.
+func (op *Options) WithTeamsNotifier(url string, fn types.ShouldReportFunc, formatter types.FormatMessageFunc) *Options {
 	op.optFuncs = append(op.optFuncs, func(config *Config) {
-		config.slack = slackConfig{
-			Token:     token,
-			Channel:   channel,
+		config.teams = teamsConfig{
+			URL:       url,
 			Report:    fn,
 			Formatter: formatter,
 		}
 	})
 	return op
 }
+
+// WithDiscordNotifier sends errors that have been marked as
+// errors.INTERNAL to a Discord channel via the webhook URL
+// passed. Entries are rendered as an embed coloured by
+// level, the description is the formatted message if a
+// formatter is passed, otherwise the entry message.
+func (op *Options) WithDiscordNotifier(url string, fn types.ShouldReportFunc, formatter types.FormatMessageFunc) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.discord = discordConfig{
+			URL:       url,
+			Report:    fn,
+			Formatter: formatter,
+		}
+	})
+	return op
+}
+
+// parseLevel parses a level name, such as "info".
+func parseLevel(v string) (logrus.Level, error) {
+	level, err := logrus.ParseLevel(v)
+	if err != nil {
+		return logrus.TraceLevel, errors.New("must be one of trace, debug, info, warn, error, fatal or panic")
+	}
+	return level, nil
+}
+
+// parseFormat parses a Format name, such as "json".
+func parseFormat(v string) (Format, error) {
+	switch strings.ToLower(v) {
+	case "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	case "logfmt":
+		return FormatLogfmt, nil
+	}
+	return FormatText, errors.New("must be one of text, json or logfmt")
+}
+
+// parseColour parses a ColourMode name, such as "never".
+func parseColour(v string) (ColourMode, error) {
+	switch strings.ToLower(v) {
+	case "auto":
+		return ColourAuto, nil
+	case "always":
+		return ColourAlways, nil
+	case "never":
+		return ColourNever, nil
+	}
+	return ColourAuto, errors.New("must be one of auto, always or never")
+}
+
+// parseOverflow parses an OverflowPolicy name, such as
+// "drop_oldest".
+func parseOverflow(v string) (OverflowPolicy, error) {
+	switch strings.ToLower(v) {
+	case "block":
+		return OverflowBlock, nil
+	case "drop_newest":
+		return OverflowDropNewest, nil
+	case "drop_oldest":
+		return OverflowDropOldest, nil
+	}
+	return OverflowBlock, errors.New("must be one of block, drop_newest or drop_oldest")
+}
+
+// parseInt parses a positive integer.
+func parseInt(v string) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, errors.New("must be a positive integer")
+	}
+	return n, nil
+}
+
+// validURL returns true if the value is an absolute http
+// or https URL.
+func validURL(v string) bool {
+	u, err := url.Parse(v)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// parseDuration parses a positive duration, such as "5s".
+func parseDuration(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, errors.New("must be a positive duration, such as 5s")
+	}
+	return d, nil
+}
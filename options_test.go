@@ -14,8 +14,13 @@
 package logger
 
 import (
+	"github.com/ainsleyclark/logger/internal/hooks/email"
+	"github.com/ainsleyclark/logger/internal/hooks/pagerduty"
+	"github.com/ainsleyclark/logger/internal/hooks/webhook"
 	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/mongo"
+	"time"
 )
 
 func (t *LoggerTestSuite) TestConfig_Validate() {
@@ -41,6 +46,211 @@ func (t *LoggerTestSuite) TestConfig_Validate() {
 			},
 			"workplace token cannot be nil",
 		},
+		"Slack Token Without Channel": {
+			Config{
+				service: "service",
+				slack:   slackConfig{Token: "token"},
+			},
+			nil,
+		},
+		"Slack Channel Without Token": {
+			Config{
+				service: "service",
+				slack:   slackConfig{Channel: "channel"},
+			},
+			nil,
+		},
+		"Slack Token And Webhook": {
+			Config{
+				service: "service",
+				slack:   slackConfig{Token: "token", Channel: "channel", WebhookURL: "https://hooks.slack.com/services/T/B/X"},
+			},
+			"cannot both be set",
+		},
+		"Slack Webhook URL": {
+			Config{
+				service: "service",
+				slack:   slackConfig{WebhookURL: "hooks.slack.com"},
+			},
+			"slack webhook url must be a valid http or https url",
+		},
+		"Slack Webhook Thread": {
+			Config{
+				service: "service",
+				slack:   slackConfig{WebhookURL: "https://hooks.slack.com/services/T/B/X", Thread: time.Hour},
+			},
+			"slack threading is not available with webhooks",
+		},
+		"Slack Thread": {
+			Config{
+				service: "service",
+				slack:   slackConfig{Token: "token", Channel: "channel", Thread: -1},
+			},
+			"slack thread window cannot be negative",
+		},
+		"Slack Webhook": {
+			Config{
+				service: "service",
+				slack:   slackConfig{WebhookURL: "https://hooks.slack.com/services/T/B/X"},
+			},
+			nil,
+		},
+		"Retry Max Attempts": {
+			Config{
+				service: "service",
+				retry:   RetryPolicy{MaxAttempts: -1},
+			},
+			"retry max attempts cannot be negative",
+		},
+		"Retry Backoff": {
+			Config{
+				service: "service",
+				retry:   RetryPolicy{BaseBackoff: -1},
+			},
+			"retry backoff cannot be negative",
+		},
+		"Retry Jitter": {
+			Config{
+				service: "service",
+				retry:   RetryPolicy{Jitter: 2},
+			},
+			"retry jitter must be between 0 and 1",
+		},
+		"Mongo Queue Size": {
+			Config{
+				service: "service",
+				mongo:   mongoConfig{QueueSize: -1},
+			},
+			"mongo queue size cannot be negative",
+		},
+		"Mongo Workers": {
+			Config{
+				service: "service",
+				mongo:   mongoConfig{Workers: -1},
+			},
+			"mongo workers cannot be negative",
+		},
+		"Mongo Batch Size": {
+			Config{
+				service: "service",
+				mongo:   mongoConfig{BatchSize: -1},
+			},
+			"mongo batch size cannot be negative",
+		},
+		"Mongo Batch Interval": {
+			Config{
+				service: "service",
+				mongo:   mongoConfig{BatchInterval: -1},
+			},
+			"mongo batch interval cannot be negative",
+		},
+		"Email From": {
+			Config{
+				service: "service",
+				email:   emailConfig{Options: email.Options{Host: "localhost"}},
+			},
+			"email from address cannot be empty",
+		},
+		"Email To": {
+			Config{
+				service: "service",
+				email:   emailConfig{Options: email.Options{Host: "localhost", From: "from@example.com"}},
+			},
+			"email must have at least one recipient",
+		},
+		"Email Timeout": {
+			Config{
+				service: "service",
+				email:   emailConfig{Options: email.Options{Host: "localhost", From: "from@example.com", To: []string{"to@example.com"}, Timeout: -1}},
+			},
+			"email timeout cannot be negative",
+		},
+		"PagerDuty Timeout": {
+			Config{
+				service:   "service",
+				pagerDuty: pagerDutyConfig{Options: pagerduty.Options{Timeout: -1}},
+			},
+			"pagerduty timeout cannot be negative",
+		},
+		"Webhook URL": {
+			Config{
+				service:  "service",
+				webhooks: []webhookConfig{{}},
+			},
+			"webhook url cannot be empty",
+		},
+		"Webhook Timeout": {
+			Config{
+				service:  "service",
+				webhooks: []webhookConfig{{Options: webhook.Options{URL: "http://localhost", Timeout: -1}}},
+			},
+			"webhook timeout cannot be negative",
+		},
+		"Rate Limit": {
+			Config{
+				service:   "service",
+				rateLimit: rateLimitConfig{Limit: -1},
+			},
+			"rate limit cannot be negative",
+		},
+		"Rate Limit Window": {
+			Config{
+				service:   "service",
+				rateLimit: rateLimitConfig{Window: -1},
+			},
+			"rate limit window cannot be negative",
+		},
+		"Digest Window": {
+			Config{
+				service: "service",
+				digest:  digestConfig{Window: -1},
+			},
+			"digest window cannot be negative",
+		},
+		"Level": {
+			Config{
+				service: "service",
+				level:   func() *logrus.Level { l := logrus.Level(10); return &l }(),
+			},
+			"level is invalid",
+		},
+		"Sink Level": {
+			Config{
+				service:    "service",
+				sinkLevels: map[string]logrus.Level{SinkSlack: 10},
+			},
+			"level for sink slack is invalid",
+		},
+		"Mongo Database": {
+			Config{
+				service: "service",
+				mongo:   mongoConfig{URI: "mongodb://localhost", Name: "logs"},
+			},
+			"mongo database cannot be empty",
+		},
+		"Mongo Collection": {
+			Config{
+				service: "service",
+				mongo:   mongoConfig{URI: "mongodb://localhost", Database: "logs"},
+			},
+			"mongo collection cannot be empty",
+		},
+		"Dead Letter Max Size": {
+			Config{
+				service:    "service",
+				deadLetter: deadLetterConfig{Dir: "dlq", MaxSize: -1},
+			},
+			"dead letter max size cannot be negative",
+		},
+		"Mongo Expiration": {
+			Config{
+				service: "service",
+				mongo: mongoConfig{Expiration: ExpirationLevels{
+					logrus.InfoLevel: time.Millisecond,
+				}},
+			},
+			"mongo expiration for level info must be at least one second",
+		},
 		"Success": {
 			Config{
 				service:   "service",
@@ -57,6 +267,7 @@ func (t *LoggerTestSuite) TestConfig_Validate() {
 				t.Contains(got.Error(), test.want)
 				return
 			}
+			t.Nil(test.want)
 		})
 	}
 }
@@ -76,6 +287,23 @@ func (t *LoggerTestSuite) TestConfig_AssignDefaults() {
 	t.NotNil(got.workplace.Report)
 	t.NotNil(got.mongo.Report)
 	t.NotNil(got.slack.Report)
+	t.NotNil(got.teams.Report)
+	t.NotNil(got.discord.Report)
+	t.NotNil(got.email.Report)
+	t.NotNil(got.pagerDuty.Report)
+	t.NotNil(got.digest.Report)
+	t.Equal(DefaultMongoQueueSize, got.mongo.QueueSize)
+	t.Equal(DefaultMongoWorkers, got.mongo.Workers)
+	t.Equal(time.Duration(0), got.mongo.BatchInterval)
+	t.Equal(DefaultMongoExpiration(), got.mongo.Expiration)
+	t.Equal(DefaultRetryPolicy(), got.retry)
+	t.Equal(logrus.TraceLevel, *got.level)
+	t.Equal(int64(DefaultDeadLetterMaxSize), got.deadLetter.MaxSize)
+	t.Equal(DefaultDeadLetterReplayInterval, got.deadLetter.ReplayInterval)
+
+	c = Config{mongo: mongoConfig{BatchSize: 10}}
+	got = c.assignDefaults()
+	t.Equal(DefaultMongoBatchInterval, got.mongo.BatchInterval)
 }
 
 func (t *LoggerTestSuite) TestOptions() {
@@ -84,9 +312,16 @@ func (t *LoggerTestSuite) TestOptions() {
 		Version("v0.0.1").
 		DefaultStatus("status").
 		Prefix("prefix").
+		Format(FormatJSON).
+		Level(logrus.InfoLevel).
+		Colour(ColourAlways).
 		WithMongoCollection(&mongo.Collection{}, types.DefaultReportFn).
 		WithWorkplaceNotifier("token", "thread", types.DefaultReportFn, nil).
-		WithSlackNotifier("token", "channel", types.DefaultReportFn, nil)
+		WithSlackNotifier("token", "channel", types.DefaultReportFn, nil).
+		Retry(RetryPolicy{MaxAttempts: 5}).
+		OnError(func(err error) {}).
+		WithTeamsNotifier("url", types.DefaultReportFn, nil).
+		WithDiscordNotifier("url", types.DefaultReportFn, nil)
 
 	c := &Config{}
 	for _, optFn := range opts.optFuncs {
@@ -97,11 +332,73 @@ func (t *LoggerTestSuite) TestOptions() {
 	t.Equal("v0.0.1", c.version)
 	t.Equal("status", c.defaultStatus)
 	t.Equal("prefix", c.prefix)
+	t.Equal(FormatJSON, c.format)
+	t.Equal(logrus.InfoLevel, *c.level)
+	t.Equal(ColourAlways, c.colour)
+	t.Equal(RetryPolicy{MaxAttempts: 5}, c.retry)
+	t.NotNil(c.onError)
 	t.Equal("token", c.workplace.Token)
 	t.Equal("thread", c.workplace.Thread)
 	t.Equal("token", c.slack.Token)
 	t.Equal("channel", c.slack.Channel)
+	t.Equal("url", c.teams.URL)
+	t.Equal("url", c.discord.URL)
 	t.NotNil(c.workplace.Report)
 	t.NotNil(c.mongo.Report)
 	t.NotNil(c.slack.Report)
+	t.NotNil(c.teams.Report)
+	t.NotNil(c.discord.Report)
+}
+
+func (t *LoggerTestSuite) TestMongoOptions() {
+	col := &mongo.Collection{}
+	opts := NewOptions().
+		WithMongo(NewMongoOptions(col).
+			Report(types.DefaultReportFn).
+			Queue(10, 2).
+			Overflow(OverflowDropOldest).
+			Batch(100, time.Second).
+			Expiration(ExpirationLevels{logrus.InfoLevel: time.Hour}).
+			DisableExpiration(logrus.ErrorLevel))
+
+	c := &Config{}
+	for _, optFn := range opts.optFuncs {
+		optFn(c)
+	}
+
+	t.Equal(col, c.mongo.Collection)
+	t.NotNil(c.mongo.Report)
+	t.Equal(10, c.mongo.QueueSize)
+	t.Equal(2, c.mongo.Workers)
+	t.Equal(OverflowDropOldest, c.mongo.Overflow)
+	t.Equal(100, c.mongo.BatchSize)
+	t.Equal(time.Second, c.mongo.BatchInterval)
+	t.Equal(ExpirationLevels{logrus.InfoLevel: time.Hour}, c.mongo.Expiration)
+	t.Equal([]logrus.Level{logrus.ErrorLevel}, c.mongo.NoExpiry)
+}
+
+func (t *LoggerTestSuite) TestMongoURIOptions() {
+	opts := NewOptions().WithMongo(NewMongoURIOptions("mongodb://localhost", "database", "collection"))
+	c := &Config{}
+	for _, optFn := range opts.optFuncs {
+		optFn(c)
+	}
+	t.Equal("mongodb://localhost", c.mongo.URI)
+	t.Equal("database", c.mongo.Database)
+	t.Equal("collection", c.mongo.Name)
+}
+
+func (t *LoggerTestSuite) TestMongoConfig_ExpirationLevels() {
+	c := mongoConfig{
+		Expiration: DefaultMongoExpiration(),
+		NoExpiry:   []logrus.Level{logrus.PanicLevel, logrus.FatalLevel},
+	}
+	got := c.expirationLevels()
+	t.NotContains(got, logrus.PanicLevel)
+	t.NotContains(got, logrus.FatalLevel)
+	t.Equal(time.Hour*24, got[logrus.TraceLevel])
+	t.Len(got, 5)
+
+	// The original levels should not be modified.
+	t.Len(c.Expiration, 7)
 }
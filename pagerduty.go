@@ -0,0 +1,124 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/hooks/pagerduty"
+	"github.com/ainsleyclark/logger/types"
+	"time"
+)
+
+// pagerDutyConfig is the configuration used to send events
+// to PagerDuty.
+type pagerDutyConfig struct {
+	Options   pagerduty.Options
+	Report    types.ShouldReportFunc
+	Formatter types.FormatMessageFunc
+}
+
+// enabled returns true if a routing key has been set.
+func (c pagerDutyConfig) enabled() bool {
+	return c.Options.RoutingKey != ""
+}
+
+// pagerDutyOptionFunc is a function type that configures a
+// pagerDutyConfig instance.
+type pagerDutyOptionFunc func(config *pagerDutyConfig)
+
+// PagerDutyOptions is the type used to configure the
+// PagerDuty notifier.
+type PagerDutyOptions struct {
+	optFuncs []pagerDutyOptionFunc
+}
+
+// PagerDutyReportFn is the default report function for
+// PagerDuty, only entries with an errors.INTERNAL error
+// trigger an incident.
+var PagerDutyReportFn = func(e types.Entry) bool {
+	err := e.Error()
+	return err != nil && err.Code == errors.INTERNAL
+}
+
+// NewPagerDutyOptions creates a PagerDutyOptions instance
+// that triggers events using the integration's routing
+// key passed.
+func NewPagerDutyOptions(routingKey string) *PagerDutyOptions {
+	op := &PagerDutyOptions{}
+	op.optFuncs = append(op.optFuncs, func(config *pagerDutyConfig) {
+		config.Options.RoutingKey = routingKey
+	})
+	return op
+}
+
+// BaseURL sets the URL of the Events API, defaults to
+// https://events.pagerduty.com.
+func (op *PagerDutyOptions) BaseURL(url string) *PagerDutyOptions {
+	op.optFuncs = append(op.optFuncs, func(config *pagerDutyConfig) {
+		config.Options.BaseURL = url
+	})
+	return op
+}
+
+// Source sets the affected system of the event, such as
+// the hostname, defaults to the service name.
+func (op *PagerDutyOptions) Source(source string) *PagerDutyOptions {
+	op.optFuncs = append(op.optFuncs, func(config *pagerDutyConfig) {
+		config.Options.Source = source
+	})
+	return op
+}
+
+// Timeout sets the maximum amount of time to wait for
+// PagerDuty to respond, defaults to 10 seconds.
+func (op *PagerDutyOptions) Timeout(timeout time.Duration) *PagerDutyOptions {
+	op.optFuncs = append(op.optFuncs, func(config *pagerDutyConfig) {
+		config.Options.Timeout = timeout
+	})
+	return op
+}
+
+// Report is the callback function to determine if the
+// entry should trigger an event, defaults to
+// PagerDutyReportFn.
+func (op *PagerDutyOptions) Report(fn types.ShouldReportFunc) *PagerDutyOptions {
+	op.optFuncs = append(op.optFuncs, func(config *pagerDutyConfig) {
+		config.Report = fn
+	})
+	return op
+}
+
+// Formatter is the function used to create the summary of
+// the event.
+func (op *PagerDutyOptions) Formatter(fn types.FormatMessageFunc) *PagerDutyOptions {
+	op.optFuncs = append(op.optFuncs, func(config *pagerDutyConfig) {
+		config.Formatter = fn
+	})
+	return op
+}
+
+// WithPagerDutyNotifier triggers PagerDuty incidents using
+// the Events API v2 with the PagerDuty specific options
+// passed. The severity is mapped from the level and
+// events are deduplicated by the error code and
+// operation.
+func (op *Options) WithPagerDutyNotifier(opts *PagerDutyOptions) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.pagerDuty = pagerDutyConfig{}
+		for _, optFn := range opts.optFuncs {
+			optFn(&config.pagerDuty)
+		}
+	})
+	return op
+}
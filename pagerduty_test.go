@@ -0,0 +1,128 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/internal/hooks/pagerduty"
+	"github.com/ainsleyclark/logger/types"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func (t *LoggerTestSuite) TestPagerDutyOptions() {
+	opts := NewOptions().
+		WithPagerDutyNotifier(NewPagerDutyOptions("key").
+			BaseURL("http://localhost").
+			Source("host").
+			Timeout(time.Second).
+			Report(types.DefaultReportFn).
+			Formatter(types.DefaultFormatMessageFn))
+
+	c := &Config{}
+	for _, optFn := range opts.optFuncs {
+		optFn(c)
+	}
+
+	t.Equal(pagerduty.Options{
+		RoutingKey: "key",
+		BaseURL:    "http://localhost",
+		Source:     "host",
+		Timeout:    time.Second,
+	}, c.pagerDuty.Options)
+	t.NotNil(c.pagerDuty.Report)
+	t.NotNil(c.pagerDuty.Formatter)
+}
+
+func (t *LoggerTestSuite) TestPagerDutyReportFn() {
+	tt := map[string]struct {
+		input types.Entry
+		want  bool
+	}{
+		"Internal": {
+			types.Entry{Data: map[string]any{types.ErrorKey: errors.NewInternal(errors.New("error"), "message", "op")}},
+			true,
+		},
+		"Invalid": {
+			types.Entry{Data: map[string]any{types.ErrorKey: errors.NewInvalid(errors.New("error"), "message", "op")}},
+			false,
+		},
+		"No Error": {
+			types.Entry{},
+			false,
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			t.Equal(test.want, PagerDutyReportFn(test.input))
+		})
+	}
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_PagerDuty() {
+	got := make(chan pagerduty.Event, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e pagerduty.Event
+		t.NoError(json.NewDecoder(r.Body).Decode(&e))
+		got <- e
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	opts := NewOptions().
+		Service("service").
+		WithPagerDutyNotifier(NewPagerDutyOptions("key").BaseURL(server.URL))
+
+	l, err := NewLogger(context.Background(), opts)
+	t.NoError(err)
+
+	l.Error("not internal")
+	l.WithError(errors.NewInternal(errors.New("error"), "message", "op")).Error()
+	t.NoError(l.Flush(context.Background()))
+
+	t.Len(got, 1)
+	e := <-got
+	t.Equal("internal:op", e.DedupKey)
+	t.Equal("error", e.Payload.Severity)
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_PagerDutyFields() {
+	got := make(chan pagerduty.Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e pagerduty.Event
+		t.NoError(json.NewDecoder(r.Body).Decode(&e))
+		got <- e
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	l, err := NewLogger(context.Background(), NewOptions().
+		Service("service").
+		WithPagerDutyNotifier(NewPagerDutyOptions("key").BaseURL(server.URL)))
+	t.NoError(err)
+
+	l.WithFields(types.Fields{"user": "alice"}).
+		WithError(errors.NewInternal(errors.New("error"), "message", "op")).
+		Error()
+	t.NoError(l.Flush(context.Background()))
+
+	t.Len(got, 1)
+	e := <-got
+	t.Equal("alice", e.Payload.CustomDetails["user"])
+	t.Equal("op", e.Payload.CustomDetails["operation"])
+}
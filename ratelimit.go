@@ -0,0 +1,78 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"github.com/ainsleyclark/logger/internal/ratelimit"
+	"github.com/ainsleyclark/logger/types"
+	"time"
+)
+
+// rateLimitConfig is the configuration used for limiting
+// similar entries sent to notifiers.
+type rateLimitConfig struct {
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimit limits the amount of similar entries sent to
+// each notifier to limit per window, entries are keyed
+// on their fingerprint (see types.Entry.Fingerprint).
+// Once the window ends, a summary such as "Suppressed
+// 342 similar errors in the last 5m" is sent for any
+// entries that were suppressed. A limit of one sends
+// each distinct error once per window. Rate limiting
+// is disabled by default.
+func (op *Options) RateLimit(limit int, window time.Duration) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.rateLimit.Limit = limit
+		config.rateLimit.Window = window
+	})
+	return op
+}
+
+// addLimiters attaches a Limiter to each notifier if rate
+// limiting is enabled, summaries are delivered to the
+// notifier that suppressed the entries.
+func (hook *defaultHook) addLimiters() {
+	if hook.config.rateLimit.Window <= 0 {
+		return
+	}
+	for i := range hook.notifiers {
+		n := &hook.notifiers[i]
+		n.limiter = ratelimit.New(hook.config.rateLimit.Limit, hook.config.rateLimit.Window, func(s ratelimit.Summary) {
+			hook.deliver(*n, summaryEntry(s))
+		})
+	}
+}
+
+// flushLimiters ends the rate limiting windows and sends
+// the summaries for any suppressed entries.
+func (hook *defaultHook) flushLimiters() {
+	for _, n := range hook.notifiers {
+		if n.limiter != nil {
+			n.limiter.Flush()
+		}
+	}
+}
+
+// summaryEntry returns the entry sent to a notifier when
+// a rate limiting window ends, the last suppressed entry
+// with the summary as its message.
+func summaryEntry(s ratelimit.Summary) types.Entry {
+	e := s.Entry
+	e.Time = time.Now()
+	e.Message = s.Message()
+	return e
+}
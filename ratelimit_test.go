@@ -0,0 +1,89 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"github.com/ainsleyclark/errors"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+func (t *LoggerTestSuite) TestRateLimit_Options() {
+	opts := NewOptions().RateLimit(5, time.Minute)
+	c := &Config{}
+	for _, optFn := range opts.optFuncs {
+		optFn(c)
+	}
+	t.Equal(rateLimitConfig{Limit: 5, Window: time.Minute}, c.rateLimit)
+}
+
+func (t *LoggerTestSuite) TestRateLimit() {
+	var (
+		mtx  sync.Mutex
+		sent []string
+	)
+	hook := &defaultHook{
+		logger: logrus.New(),
+		config: &Config{
+			rateLimit: rateLimitConfig{Limit: 1, Window: time.Hour},
+		},
+		notifiers: []notifierConfig{
+			{Name: "notifier", Notifier: NotifierFunc(func(ctx context.Context, entry types.Entry, args types.FormatMessageArgs) error {
+				mtx.Lock()
+				defer mtx.Unlock()
+				sent = append(sent, entry.Message)
+				return nil
+			}), Report: types.DefaultReportFn},
+		},
+	}
+	hook.addLimiters()
+
+	fire := func(msg string) {
+		t.NoError(hook.Fire(&logrus.Entry{
+			Level:   logrus.ErrorLevel,
+			Message: msg,
+			Data:    logrus.Fields{types.ErrorKey: errors.NewInternal(errors.New("error"), msg, "op")},
+		}))
+	}
+
+	for i := 0; i < 5; i++ {
+		fire("database down")
+	}
+	fire("cache down")
+	t.NoError(hook.flush(context.Background()))
+
+	mtx.Lock()
+	t.ElementsMatch([]string{"database down", "cache down"}, sent)
+	mtx.Unlock()
+
+	// Summaries are sent for the suppressed entries once the
+	// window ends.
+	t.NoError(hook.close(context.Background()))
+	mtx.Lock()
+	defer mtx.Unlock()
+	t.Len(sent, 3)
+	t.Equal("Suppressed 4 similar errors in the last 1s", sent[2])
+}
+
+func (t *LoggerTestSuite) TestRateLimit_Disabled() {
+	hook := &defaultHook{
+		config:    &Config{},
+		notifiers: []notifierConfig{{Name: "notifier"}},
+	}
+	hook.addLimiters()
+	t.Nil(hook.notifiers[0].limiter)
+}
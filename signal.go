@@ -0,0 +1,89 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// LevelSignals changes the global level of the Logger
+// when the process receives a signal. SIGUSR1 makes the
+// Logger more verbose by one level, towards trace, and
+// SIGUSR2 makes it less verbose, towards panic.
+//
+// Signals are not supported on Windows, the option has
+// no effect.
+func (op *Options) LevelSignals() *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.levelSignals = true
+	})
+	return op
+}
+
+// watchSignals steps the level of the Logger on each of
+// the levelSignals received until the returned function
+// is called.
+func (l *Logger) watchSignals() func() {
+	if len(levelSignals) == 0 {
+		return func() {}
+	}
+
+	ch := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	signal.Notify(ch, levelSignals...)
+
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case sig := <-ch:
+				l.stepLevel(signalStep(sig))
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(stop)
+			<-done
+		})
+	}
+}
+
+// stepLevel moves the global level of the Logger by n
+// steps and logs the change.
+func (l *Logger) stepLevel(n int) {
+	if n == 0 {
+		return
+	}
+	prev := l.GetLevel()
+	next := stepLevel(prev, n)
+	if next == prev {
+		return
+	}
+	l.SetLevel(next)
+	// Marked as internal so the change is only written to
+	// stdout, it's logged at the info level so it is
+	// visible unless the Logger is less verbose.
+	ctx := context.WithValue(context.Background(), internalKey{}, true)
+	l.Logger.WithContext(ctx).Infof("Log level changed from %s to %s", prev, next)
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"github.com/sirupsen/logrus"
+)
+
+func (t *LoggerTestSuite) TestStepLevel() {
+	tt := map[string]struct {
+		level logrus.Level
+		n     int
+		want  logrus.Level
+	}{
+		"Verbose":     {logrus.InfoLevel, 1, logrus.DebugLevel},
+		"Quiet":       {logrus.InfoLevel, -1, logrus.WarnLevel},
+		"Max Verbose": {logrus.TraceLevel, 1, logrus.TraceLevel},
+		"Max Quiet":   {logrus.PanicLevel, -1, logrus.PanicLevel},
+		"Many":        {logrus.ErrorLevel, 10, logrus.TraceLevel},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func() {
+			t.Equal(test.want, stepLevel(test.level, test.n))
+		})
+	}
+}
+
+func (t *LoggerTestSuite) TestLogger_StepLevel() {
+	buf := &bytes.Buffer{}
+	l := &Logger{Logger: logrus.New()}
+	l.SetOutput(buf)
+	l.SetLevel(logrus.InfoLevel)
+
+	l.stepLevel(1)
+	t.Equal(logrus.DebugLevel, l.GetLevel())
+	t.Contains(buf.String(), "Log level changed from info to debug")
+
+	buf.Reset()
+	l.SetLevel(logrus.TraceLevel)
+	l.stepLevel(1)
+	t.Equal(logrus.TraceLevel, l.GetLevel())
+	t.Empty(buf.String())
+}
+
+func (t *LoggerTestSuite) TestLogger_WatchSignals() {
+	l, err := NewLogger(context.TODO(), NewOptions().Service("service").LevelSignals())
+	t.NoError(err)
+	t.NotNil(l.stopSignals)
+	t.NoError(l.Close(context.TODO()))
+	// Stopping twice should not panic.
+	l.stopSignals()
+}
@@ -11,21 +11,27 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package hooks
+//go:build !windows
 
-import "github.com/ainsleyclark/logger/types"
+package logger
 
-func GetMessage(entry types.Entry, args types.FormatMessageArgs, fmt types.FormatMessageFunc) string {
-	// Setup args for formatting the message.
-	var message = "" //nolint
+import (
+	"os"
+	"syscall"
+)
 
-	// Use the default format message if none is attached,
-	// otherwise call the function that is assigned.
-	if fmt == nil {
-		message = types.DefaultFormatMessageFn(entry, args)
-	} else {
-		message = fmt(entry, args)
-	}
+// levelSignals are the signals that change the level of
+// the Logger when LevelSignals is enabled.
+var levelSignals = []os.Signal{syscall.SIGUSR1, syscall.SIGUSR2}
 
-	return message
+// signalStep returns the amount of levels to move by when
+// the signal is received, SIGUSR1 is more verbose.
+func signalStep(sig os.Signal) int {
+	switch sig {
+	case syscall.SIGUSR1:
+		return 1
+	case syscall.SIGUSR2:
+		return -1
+	}
+	return 0
 }
@@ -0,0 +1,43 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+
+package logger
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"syscall"
+	"time"
+)
+
+func (t *LoggerTestSuite) TestLogger_LevelSignals() {
+	l, err := NewLogger(context.TODO(), NewOptions().
+		Service("service").
+		Level(logrus.InfoLevel).
+		LevelSignals())
+	t.NoError(err)
+	defer l.Close(context.TODO()) //nolint
+
+	send := func(sig syscall.Signal, want logrus.Level) {
+		t.NoError(syscall.Kill(syscall.Getpid(), sig))
+		t.Eventually(func() bool {
+			return l.GetLevel() == want
+		}, time.Second, time.Millisecond*10)
+	}
+
+	send(syscall.SIGUSR1, logrus.DebugLevel)
+	send(syscall.SIGUSR2, logrus.InfoLevel)
+	send(syscall.SIGUSR2, logrus.WarnLevel)
+}
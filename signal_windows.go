@@ -0,0 +1,27 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package logger
+
+import "os"
+
+// levelSignals is empty as SIGUSR1 and SIGUSR2 are not
+// available on Windows.
+var levelSignals []os.Signal
+
+// signalStep always returns zero on Windows.
+func signalStep(_ os.Signal) int {
+	return 0
+}
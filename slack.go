@@ -0,0 +1,123 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"github.com/ainsleyclark/logger/internal/hooks/slack"
+	"github.com/ainsleyclark/logger/types"
+	goslack "github.com/slack-go/slack"
+	"time"
+)
+
+// SlackBlockBuilder is the function used to create the
+// Block Kit blocks sent to Slack for an entry.
+type SlackBlockBuilder = slack.BlockBuilder
+
+// DefaultSlackBlocks creates the default Block Kit layout
+// for the entry. A header with the service and version,
+// a section with the level and message, fields for the
+// error's code, operation and fileline, a context with
+// the timestamp and a code block of the entry's data.
+func DefaultSlackBlocks(entry types.Entry, args types.FormatMessageArgs) []goslack.Block {
+	return slack.DefaultBlocks(entry, args)
+}
+
+// slackOptionFunc is a function type that configures a
+// slackConfig instance.
+type slackOptionFunc func(config *slackConfig)
+
+// SlackOptions is the type used to configure the Slack
+// notifier.
+type SlackOptions struct {
+	optFuncs []slackOptionFunc
+}
+
+// NewSlackOptions creates a SlackOptions instance that
+// posts entries to the channel passed using a bot
+// token.
+func NewSlackOptions(token, channel string) *SlackOptions {
+	op := &SlackOptions{}
+	op.optFuncs = append(op.optFuncs, func(config *slackConfig) {
+		config.Token = token
+		config.Channel = channel
+	})
+	return op
+}
+
+// NewSlackWebhookOptions creates a SlackOptions instance
+// that posts entries to the incoming webhook URL passed,
+// no bot token is required.
+func NewSlackWebhookOptions(url string) *SlackOptions {
+	op := &SlackOptions{}
+	op.optFuncs = append(op.optFuncs, func(config *slackConfig) {
+		config.WebhookURL = url
+	})
+	return op
+}
+
+// Report is the callback function to determine if the
+// entry should be sent to Slack.
+func (op *SlackOptions) Report(fn types.ShouldReportFunc) *SlackOptions {
+	op.optFuncs = append(op.optFuncs, func(config *slackConfig) {
+		config.Report = fn
+	})
+	return op
+}
+
+// Formatter is the function used to create the text of
+// the message, which is shown within notifications.
+func (op *SlackOptions) Formatter(fn types.FormatMessageFunc) *SlackOptions {
+	op.optFuncs = append(op.optFuncs, func(config *slackConfig) {
+		config.Formatter = fn
+	})
+	return op
+}
+
+// Blocks sets the function used to build the Block Kit
+// layout of the message, defaults to DefaultSlackBlocks.
+// The blocks are shown within an attachment coloured by
+// the entry's level.
+func (op *SlackOptions) Blocks(fn SlackBlockBuilder) *SlackOptions {
+	op.optFuncs = append(op.optFuncs, func(config *slackConfig) {
+		config.Blocks = fn
+	})
+	return op
+}
+
+// Thread posts repeated errors as replies to the message
+// of the first occurrence, rather than flooding the
+// channel, and updates the occurrence counter on it.
+// Entries are grouped by their types.Entry Fingerprint,
+// the error's code, operation and message. Once an
+// error hasn't been seen for the window, the next
+// occurrence starts a new thread. Threading is not
+// available with incoming webhooks.
+func (op *SlackOptions) Thread(window time.Duration) *SlackOptions {
+	op.optFuncs = append(op.optFuncs, func(config *slackConfig) {
+		config.Thread = window
+	})
+	return op
+}
+
+// WithSlack sends entries to Slack with the Slack specific
+// options passed.
+func (op *Options) WithSlack(opts *SlackOptions) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		config.slack = slackConfig{}
+		for _, optFn := range opts.optFuncs {
+			optFn(&config.slack)
+		}
+	})
+	return op
+}
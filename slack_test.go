@@ -0,0 +1,66 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"github.com/ainsleyclark/logger/types"
+	goslack "github.com/slack-go/slack"
+	"time"
+)
+
+func (t *LoggerTestSuite) TestSlackOptions() {
+	opts := NewOptions().
+		WithSlack(NewSlackOptions("token", "channel").
+			Report(types.DefaultReportFn).
+			Formatter(types.DefaultFormatMessageFn).
+			Thread(time.Hour).
+			Blocks(func(entry types.Entry, args types.FormatMessageArgs) []goslack.Block {
+				return nil
+			}))
+
+	c := &Config{}
+	for _, optFn := range opts.optFuncs {
+		optFn(c)
+	}
+
+	t.Equal("token", c.slack.Token)
+	t.Equal("channel", c.slack.Channel)
+	t.NotNil(c.slack.Report)
+	t.NotNil(c.slack.Formatter)
+	t.NotNil(c.slack.Blocks)
+	t.Equal(time.Hour, c.slack.Thread)
+}
+
+func (t *LoggerTestSuite) TestSlackWebhookOptions() {
+	opts := NewOptions().
+		WithSlack(NewSlackWebhookOptions("https://hooks.slack.com/services/T/B/X"))
+
+	c := &Config{}
+	for _, optFn := range opts.optFuncs {
+		optFn(c)
+	}
+
+	t.Equal("https://hooks.slack.com/services/T/B/X", c.slack.WebhookURL)
+	t.Equal("", c.slack.Token)
+
+	hook := &defaultHook{config: c.assignDefaults()}
+	t.NoError(hook.addNotifiers())
+	t.Len(hook.notifiers, 1)
+	t.Equal("slack", hook.notifiers[0].Name)
+}
+
+func (t *LoggerTestSuite) TestDefaultSlackBlocks() {
+	got := DefaultSlackBlocks(types.Entry{Message: "message"}, types.FormatMessageArgs{Service: "api"})
+	t.Len(got, 2)
+}
@@ -42,12 +42,17 @@ func (t *LoggerTestSuite) TearDownTestSuite() {
 // suite.
 func (t *LoggerTestSuite) Setup() *bytes.Buffer {
 	buf := &bytes.Buffer{}
-	L.SetLevel(logrus.TraceLevel)
-	L.SetOutput(buf)
 	c := Config{}
-	L.SetFormatter(&formatter{
-		Config:  c.assignDefaults(),
+	l := &Logger{
+		Logger: logrus.New(),
+		config: c.assignDefaults(),
+	}
+	l.SetLevel(logrus.TraceLevel)
+	l.SetOutput(buf)
+	l.SetFormatter(&formatter{
+		Config:  l.config,
 		Colours: false,
 	})
+	SetDefault(l)
 	return buf
 }
@@ -15,6 +15,8 @@ package types
 
 import (
 	"bytes"
+	"crypto/sha1" //nolint
+	"encoding/hex"
 	"fmt"
 	"github.com/ainsleyclark/errors"
 	"github.com/enescakir/emoji"
@@ -40,6 +42,10 @@ type (
 		Service string
 		Version string
 		Prefix  string
+		// Formatter is the function registered alongside the
+		// notifier for formatting messages, if it's nil the
+		// DefaultFormatMessageFn is used.
+		Formatter FormatMessageFunc
 	}
 )
 
@@ -106,6 +112,15 @@ var (
 	}
 )
 
+// Message formats the entry with the Formatter, or the
+// DefaultFormatMessageFn if none is attached.
+func (a FormatMessageArgs) Message(entry Entry) string {
+	if a.Formatter == nil {
+		return DefaultFormatMessageFn(entry, a)
+	}
+	return a.Formatter(entry, a)
+}
+
 // ToLogrusEntry transforms an Entry to logrus.Entry
 func (e Entry) ToLogrusEntry() logrus.Entry {
 	return logrus.Entry(e)
@@ -130,11 +145,15 @@ func (e Entry) Fields() Fields {
 	if !ok {
 		return nil
 	}
-	fields, ok := in.(map[string]any)
-	if !ok {
-		return nil
+	// Fields are stored as a logrus.Fields by the Logger
+	// but may also be set as a plain map.
+	switch fields := in.(type) {
+	case Fields:
+		return fields
+	case map[string]any:
+		return fields
 	}
-	return fields
+	return nil
 }
 
 // HasError determines if an error is attached to
@@ -160,3 +179,22 @@ func (e Entry) Error() *errors.Error {
 	}
 	return errors.ToError(err)
 }
+
+// Fingerprint returns a hash that identifies similar
+// entries, made up of the error's code, operation and
+// message. If there is no error attached, the level
+// and message of the entry is used.
+func (e Entry) Fingerprint() string {
+	var parts []string
+	if err := e.Error(); err != nil {
+		msg := err.Message
+		if msg == "" && err.Err != nil {
+			msg = err.Err.Error()
+		}
+		parts = []string{err.Code, err.Operation, msg}
+	} else {
+		parts = []string{e.Level.String(), e.Message}
+	}
+	sum := sha1.Sum([]byte(strings.Join(parts, "\x00"))) //nolint
+	return hex.EncodeToString(sum[:])
+}
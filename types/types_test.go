@@ -45,6 +45,33 @@ func TestHook_FormatMessage(t *testing.T) {
 	assert.Contains(t, got, "Prefix")
 }
 
+func TestFormatMessageArgs_Message(t *testing.T) {
+	entry := Entry{Message: "message"}
+
+	tt := map[string]struct {
+		formatter FormatMessageFunc
+		want      any
+	}{
+		"Nil Format": {
+			nil,
+			"message",
+		},
+		"With Format": {
+			func(entry Entry, args FormatMessageArgs) string {
+				return "hello " + args.Service
+			},
+			"hello service",
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			got := FormatMessageArgs{Service: "service", Formatter: test.formatter}.Message(entry)
+			assert.Contains(t, got, test.want)
+		})
+	}
+}
+
 func TestEntry_ToLogrusEntry(t *testing.T) {
 	e := Entry{}
 	got := e.ToLogrusEntry()
@@ -107,6 +134,10 @@ func TestEntry_Fields(t *testing.T) {
 			},
 			Fields{"test": "hello"},
 		},
+		"Logrus Fields": {
+			Entry(*logrus.WithFields(logrus.Fields{FieldKey: Fields{"test": "hello"}})),
+			Fields{"test": "hello"},
+		},
 	}
 
 	for name, test := range tt {
@@ -184,3 +215,52 @@ func TestEntry_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestEntry_Fingerprint(t *testing.T) {
+	internal := func(msg, op string) Entry {
+		return Entry{Data: map[string]any{ErrorKey: errors.NewInternal(errors.New("error"), msg, op)}}
+	}
+
+	tt := map[string]struct {
+		a, b Entry
+		want bool
+	}{
+		"Same Error": {
+			internal("message", "op"),
+			internal("message", "op"),
+			true,
+		},
+		"Different Message": {
+			internal("message", "op"),
+			internal("other", "op"),
+			false,
+		},
+		"Different Operation": {
+			internal("message", "op"),
+			internal("message", "other"),
+			false,
+		},
+		"Different Code": {
+			internal("message", "op"),
+			Entry{Data: map[string]any{ErrorKey: errors.NewInvalid(errors.New("error"), "message", "op")}},
+			false,
+		},
+		"Ignores Fields": {
+			Entry{Level: logrus.InfoLevel, Message: "message", Data: map[string]any{"key": 1}},
+			Entry{Level: logrus.InfoLevel, Message: "message", Data: map[string]any{"key": 2}},
+			true,
+		},
+		"Different Level": {
+			Entry{Level: logrus.InfoLevel, Message: "message"},
+			Entry{Level: logrus.WarnLevel, Message: "message"},
+			false,
+		},
+	}
+
+	for name, test := range tt {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.a.Fingerprint() == test.b.Fingerprint())
+			assert.Len(t, test.a.Fingerprint(), 40)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"github.com/ainsleyclark/logger/internal/hooks"
+	"github.com/ainsleyclark/logger/internal/hooks/webhook"
+	"github.com/ainsleyclark/logger/types"
+	"time"
+)
+
+// DefaultWebhookTimeout is the default amount of time to
+// wait for a webhook endpoint to respond.
+const DefaultWebhookTimeout = hooks.DefaultTimeout
+
+// WebhookPayload is the data passed to the webhook body
+// template.
+type WebhookPayload = webhook.Payload
+
+// webhookConfig is the configuration used to send to
+// an HTTP endpoint.
+type webhookConfig struct {
+	Name      string
+	Options   webhook.Options
+	Report    types.ShouldReportFunc
+	Formatter types.FormatMessageFunc
+}
+
+// name returns the name of the webhook, SinkWebhook if
+// none has been set.
+func (c webhookConfig) name() string {
+	if c.Name == "" {
+		return SinkWebhook
+	}
+	return c.Name
+}
+
+// webhookOptionFunc is a function type that configures a
+// webhookConfig instance.
+type webhookOptionFunc func(config *webhookConfig)
+
+// WebhookOptions is the type used to configure a webhook
+// notifier.
+type WebhookOptions struct {
+	optFuncs []webhookOptionFunc
+}
+
+// NewWebhookOptions creates a WebhookOptions instance that
+// POSTs entries to the URL passed.
+func NewWebhookOptions(url string) *WebhookOptions {
+	op := &WebhookOptions{}
+	op.optFuncs = append(op.optFuncs, func(config *webhookConfig) {
+		config.Options.URL = url
+	})
+	return op
+}
+
+// Name sets the name of the webhook, used to refer to it
+// such as with SinkLevel. It must be set if there is
+// more than one webhook, defaults to SinkWebhook.
+func (op *WebhookOptions) Name(name string) *WebhookOptions {
+	op.optFuncs = append(op.optFuncs, func(config *webhookConfig) {
+		config.Name = name
+	})
+	return op
+}
+
+// Header adds a header to every request.
+func (op *WebhookOptions) Header(key, value string) *WebhookOptions {
+	op.optFuncs = append(op.optFuncs, func(config *webhookConfig) {
+		if config.Options.Headers == nil {
+			config.Options.Headers = make(map[string]string)
+		}
+		config.Options.Headers[key] = value
+	})
+	return op
+}
+
+// Template sets the text/template used to render the JSON
+// body, a WebhookPayload is passed as the data and the
+// json function quotes values, for example:
+//
+//	{"text": {{ json .Text }}, "level": {{ json .Level }}}
+//
+// By default, the WebhookPayload is encoded as JSON.
+func (op *WebhookOptions) Template(tmpl string) *WebhookOptions {
+	op.optFuncs = append(op.optFuncs, func(config *webhookConfig) {
+		config.Options.Template = tmpl
+	})
+	return op
+}
+
+// Secret signs the request body with HMAC-SHA256, the hex
+// encoded signature is sent within the header passed.
+// If the header is empty, X-Logger-Signature is used.
+func (op *WebhookOptions) Secret(secret, header string) *WebhookOptions {
+	op.optFuncs = append(op.optFuncs, func(config *webhookConfig) {
+		config.Options.Secret = secret
+		config.Options.SignatureHeader = header
+	})
+	return op
+}
+
+// Timeout sets the maximum amount of time to wait for the
+// endpoint to respond, defaults to DefaultWebhookTimeout.
+func (op *WebhookOptions) Timeout(timeout time.Duration) *WebhookOptions {
+	op.optFuncs = append(op.optFuncs, func(config *webhookConfig) {
+		config.Options.Timeout = timeout
+	})
+	return op
+}
+
+// Report is the callback function to determine if the
+// entry should be sent to the endpoint.
+func (op *WebhookOptions) Report(fn types.ShouldReportFunc) *WebhookOptions {
+	op.optFuncs = append(op.optFuncs, func(config *webhookConfig) {
+		config.Report = fn
+	})
+	return op
+}
+
+// Formatter is the function used to create the Text of the
+// WebhookPayload.
+func (op *WebhookOptions) Formatter(fn types.FormatMessageFunc) *WebhookOptions {
+	op.optFuncs = append(op.optFuncs, func(config *webhookConfig) {
+		config.Formatter = fn
+	})
+	return op
+}
+
+// WithWebhookNotifier sends entries to an HTTP endpoint with
+// the webhook specific options passed. It can be called
+// more than once to send to multiple endpoints, each
+// with a unique name.
+func (op *Options) WithWebhookNotifier(opts *WebhookOptions) *Options {
+	op.optFuncs = append(op.optFuncs, func(config *Config) {
+		wh := webhookConfig{}
+		for _, optFn := range opts.optFuncs {
+			optFn(&wh)
+		}
+		config.webhooks = append(config.webhooks, wh)
+	})
+	return op
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 Ainsley Clark. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ainsleyclark/logger/internal/hooks/webhook"
+	"github.com/ainsleyclark/logger/types"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func (t *LoggerTestSuite) TestWebhookOptions() {
+	opts := NewOptions().
+		WithWebhookNotifier(NewWebhookOptions("http://localhost").
+			Name("first").
+			Header("Authorization", "Bearer token").
+			Template(`{"text": {{ json .Text }}}`).
+			Secret("secret", "X-Signature").
+			Timeout(time.Second).
+			Report(types.DefaultReportFn).
+			Formatter(types.DefaultFormatMessageFn)).
+		WithWebhookNotifier(NewWebhookOptions("http://localhost:8080"))
+
+	c := &Config{}
+	for _, optFn := range opts.optFuncs {
+		optFn(c)
+	}
+	c.assignDefaults()
+
+	t.Len(c.webhooks, 2)
+	t.Equal(webhook.Options{
+		URL:             "http://localhost",
+		Headers:         map[string]string{"Authorization": "Bearer token"},
+		Template:        `{"text": {{ json .Text }}}`,
+		Secret:          "secret",
+		SignatureHeader: "X-Signature",
+		Timeout:         time.Second,
+	}, c.webhooks[0].Options)
+	t.NotNil(c.webhooks[0].Formatter)
+	t.Equal("first", c.webhooks[0].name())
+	t.Equal(SinkWebhook, c.webhooks[1].name())
+	t.Equal("http://localhost:8080", c.webhooks[1].Options.URL)
+	t.NotNil(c.webhooks[1].Report)
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_Webhook() {
+	got := make(chan WebhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p WebhookPayload
+		t.NoError(json.NewDecoder(r.Body).Decode(&p))
+		got <- p
+	}))
+	defer server.Close()
+
+	opts := NewOptions().
+		Service("service").
+		WithWebhookNotifier(NewWebhookOptions(server.URL))
+
+	l, err := NewLogger(context.Background(), opts)
+	t.NoError(err)
+
+	l.WithField("key", "value").Error("message")
+	t.NoError(l.Flush(context.Background()))
+
+	p := <-got
+	t.Equal("service", p.Service)
+	t.Equal(logrus.ErrorLevel.String(), p.Level)
+	t.Equal("message", p.Message)
+}
+
+func (t *LoggerTestSuite) TestDefaultHook_AddWebhook() {
+	cfg := &Config{webhooks: []webhookConfig{{Options: webhook.Options{URL: "http://localhost", Template: "{{ .Text"}}}}
+	hook := &defaultHook{config: cfg.assignDefaults()}
+	t.Error(hook.addNotifiers())
+}